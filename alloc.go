@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// allocate returns a pointer to a new value of type t, obtained from the allocator set with
+// WithAllocator or from reflect.New.
+func (d *Decoder) allocate(t reflect.Type) (reflect.Value, error) {
+	if d.opts.Allocator == nil {
+		return reflect.New(t), nil
+	}
+
+	p := d.opts.Allocator(t)
+	if !p.IsValid() || p.Type() != reflect.PointerTo(t) || p.IsNil() {
+		return reflect.Value{}, fmt.Errorf("allocator returned %v for %s, want non-nil *%s", p, t, t)
+	}
+	return p, nil
+}
+
+// allocatePtr sets the nil pointer dst to a newly allocated value.
+func (d *Decoder) allocatePtr(dst reflect.Value) error {
+	p, err := d.allocate(dst.Type().Elem())
+	if err != nil {
+		return err
+	}
+	dst.Set(p)
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWithAllocator(t *testing.T) {
+	type Address struct {
+		City string
+	}
+	type User struct {
+		Name    string
+		Address *Address
+		Age     *int
+	}
+
+	pool := sync.Pool{New: func() interface{} { return new(Address) }}
+	var allocated []reflect.Type
+	alloc := func(t reflect.Type) reflect.Value {
+		allocated = append(allocated, t)
+		if t == reflect.TypeFor[Address]() {
+			return reflect.ValueOf(pool.Get())
+		}
+		return reflect.New(t)
+	}
+
+	src := map[string]interface{}{
+		"Name":    "john",
+		"Address": map[string]interface{}{"City": "Berlin"},
+		"Age":     30,
+	}
+
+	var dst User
+	if err := NewDecoder(WithAllocator(alloc)).Decode(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Address == nil || dst.Age == nil {
+		t.Fatalf("expected pointers to be allocated, got %+v", dst)
+	}
+	if !reflect.ValueOf(dst.Address).Elem().CanAddr() {
+		t.Error("expected allocated value to be addressable")
+	}
+	if dst.Address.City != "Berlin" || *dst.Age != 30 {
+		t.Errorf("unexpected result: %+v, %d", dst.Address, *dst.Age)
+	}
+	if len(allocated) != 2 {
+		t.Errorf("expected 2 allocations, got %v", allocated)
+	}
+
+	t.Run("wrong type", func(t *testing.T) {
+		bad := func(reflect.Type) reflect.Value { return reflect.ValueOf(new(string)) }
+		var dst User
+		err := NewDecoder(WithAllocator(bad)).Decode(src, &dst)
+		if err == nil || !strings.Contains(err.Error(), "allocator") {
+			t.Errorf("expected allocator error, got %v", err)
+		}
+	})
+}
+
+func TestStructSliceInPlace(t *testing.T) {
+	type Item struct {
+		ID   int
+		Name string
+		Tags []string
+	}
+	type List struct {
+		Items []Item
+	}
+
+	src := map[string]interface{}{"Items": []interface{}{
+		map[string]interface{}{"ID": 1, "Name": "a", "Tags": []interface{}{"x"}},
+		map[string]interface{}{"ID": 2},
+	}}
+	var dst List
+	if err := NewDecoder().Decode(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Item{{ID: 1, Name: "a", Tags: []string{"x"}}, {ID: 2}}
+	if !reflect.DeepEqual(dst.Items, want) {
+		t.Fatalf("expected %+v, got %+v", want, dst.Items)
+	}
+
+	t.Run("no allocation per element", func(t *testing.T) {
+		allocs := func(n int) float64 {
+			points := make([]interface{}, n)
+			for i := range points {
+				points[i] = map[string]interface{}{"ID": i}
+			}
+			data := map[string]interface{}{"Items": points}
+			return testing.AllocsPerRun(10, func() {
+				var dst List
+				if err := NewDecoder().Decode(data, &dst); err != nil {
+					t.Fatal(err)
+				}
+			})
+		}
+		if small, large := allocs(100), allocs(200); large != small {
+			t.Errorf("expected allocations independent of the slice length, got %.0f for 100 and %.0f for 200",
+				small, large)
+		}
+	})
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// genericMap has the shape of map[string]interface{} but a distinct type, which keeps
+// assignMap on the reflection path.
+type genericMap map[string]interface{}
+
+func BenchmarkI2SFastPath(b *testing.B) {
+	src := map[string]interface{}{
+		"KeyInt":    42,
+		"KeyFloat":  3.14,
+		"KeyBool":   true,
+		"KeyString": "test",
+	}
+
+	decode := func(b *testing.B, data interface{}) {
+		var dst Simple
+		if err := i2s(data, &dst); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("fast", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			decode(b, src)
+		}
+	})
+	b.Run("reflect", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			decode(b, genericMap(src))
+		}
+	})
+}
+
+// TestI2SFastPathAllocs guards the comparison made by BenchmarkI2SFastPath with allocation
+// counts, which unlike timings do not depend on the machine: the fast path must allocate less
+// than the reflection path.
+func TestI2SFastPathAllocs(t *testing.T) {
+	src := map[string]interface{}{
+		"KeyInt":    42,
+		"KeyFloat":  3.14,
+		"KeyBool":   true,
+		"KeyString": "test",
+	}
+	allocs := func(data interface{}) float64 {
+		return testing.AllocsPerRun(100, func() {
+			var dst Simple
+			if err := i2s(data, &dst); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	if fast, slow := allocs(src), allocs(genericMap(src)); fast >= slow {
+		t.Errorf("expected the fast path to allocate less than the reflection path, got %.0f and %.0f", fast, slow)
+	}
+}
+
+func BenchmarkDecodeSimpleZeroAlloc(b *testing.B) {
+	src := map[string]interface{}{
+		"KeyInt":     42,
+		"KeyFloat":   3.14,
+		"KeyBool":    true,
+		"KeyComplex": complex64(1 + 2i),
+		"KeyString":  "test",
+	}
+
+	// warm the metadata cache.
+	var warm Simple
+	if err := NewDecoder().Decode(src, &warm); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		d := NewDecoder()
+		var dst Simple
+		for pb.Next() {
+			if err := d.Decode(src, &dst); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+// TestDecodeSimpleZeroAlloc guards the allocation contract of BenchmarkDecodeSimpleZeroAlloc:
+// once the metadata cache is warm, decoding plain values into a flat struct must not allocate.
+func TestDecodeSimpleZeroAlloc(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs a benchmark")
+	}
+
+	res := testing.Benchmark(BenchmarkDecodeSimpleZeroAlloc)
+	if allocs := res.AllocsPerOp(); allocs != 0 {
+		t.Errorf("expected 0 allocs/op on the warm decode path, got %d (%s)", allocs, res.MemString())
+	}
+}
+
+func BenchmarkStructMetaCache(b *testing.B) {
+	type Wide struct {
+		F0, F1, F2, F3, F4 int
+		F5, F6, F7, F8, F9 string `gomap:",group=all"`
+	}
+
+	var dst Wide
+	out := reflect.ValueOf(&dst).Elem()
+
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		d := NewDecoder()
+		for b.Loop() {
+			if _, err := d.mapStructFieldsByName(out); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		d := NewDecoder()
+		for b.Loop() {
+			d.metas = new(sync.Map)
+			if _, err := d.mapStructFieldsByName(out); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+type benchAddress struct {
+	Street string
+	City   string
+	Zip    int
+}
+
+type benchUser struct {
+	ID      int
+	Name    string
+	Email   string
+	Score   float64
+	Active  bool
+	Tags    []string
+	Address benchAddress
+	Manager *benchAddress
+}
+
+// benchNode nests itself through a slice to measure deep decoding. Each level counts twice
+// towards WithMaxDepth, once for the map and once for the slice.
+type benchNode struct {
+	Value    int
+	Children []benchNode
+}
+
+func benchUserSource(id int) map[string]interface{} {
+	return map[string]interface{}{
+		"ID":      id,
+		"Name":    "john",
+		"Email":   "john@example.com",
+		"Score":   4.5,
+		"Active":  true,
+		"Tags":    []interface{}{"admin", "ops"},
+		"Address": map[string]interface{}{"Street": "Main St", "City": "Berlin", "Zip": 10115},
+		"Manager": map[string]interface{}{"Street": "Side St", "City": "Paris", "Zip": 75001},
+	}
+}
+
+func benchNodeSource(depth int) map[string]interface{} {
+	node := map[string]interface{}{"Value": depth}
+	if depth > 0 {
+		node["Children"] = []interface{}{benchNodeSource(depth - 1)}
+	}
+	return node
+}
+
+// benchI2S decodes src into a new T with i2s on every iteration, in parallel.
+func benchI2S[T any](b *testing.B, src interface{}) {
+	b.Helper()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			var dst T
+			if err := i2s(src, &dst); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkDecodeSimpleStruct(b *testing.B) {
+	src := map[string]interface{}{
+		"KeyInt":     42,
+		"KeyFloat":   3.14,
+		"KeyBool":    true,
+		"KeyComplex": complex64(1 + 2i),
+		"KeyString":  "test",
+	}
+	benchI2S[Simple](b, src)
+}
+
+func BenchmarkDecodeNestedStruct(b *testing.B) {
+	benchI2S[benchUser](b, benchUserSource(1))
+}
+
+func BenchmarkDecodeSliceOfStructs(b *testing.B) {
+	users := make([]interface{}, 100)
+	for i := range users {
+		users[i] = benchUserSource(i)
+	}
+	benchI2S[struct{ Users []benchUser }](b, map[string]interface{}{"Users": users})
+}
+
+func BenchmarkDecodeDeepNested(b *testing.B) {
+	benchI2S[benchNode](b, benchNodeSource(25))
+}
+
+func BenchmarkDecodeWithCache(b *testing.B) {
+	src := benchUserSource(1)
+
+	b.Run("cold", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			d := NewDecoder()
+			d.metas = new(sync.Map)
+			var dst benchUser
+			if err := d.Decode(src, &dst); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("warm", func(b *testing.B) {
+		// warm the metadata cache.
+		var warm benchUser
+		if err := i2s(src, &warm); err != nil {
+			b.Fatal(err)
+		}
+		benchI2S[benchUser](b, src)
+	})
+}
+
+func BenchmarkDecodeLargeSliceOfStructs(b *testing.B) {
+	type Point struct {
+		X, Y  int
+		Label string
+	}
+	points := make([]interface{}, 10000)
+	for i := range points {
+		points[i] = map[string]interface{}{"X": i, "Y": -i, "Label": "p"}
+	}
+	src := map[string]interface{}{"Points": points}
+
+	d := NewDecoder()
+	b.ReportAllocs()
+	for b.Loop() {
+		var dst struct{ Points []Point }
+		if err := d.Decode(src, &dst); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+)
+
+// assignBigInt decodes data into out when out is a big.Int, or a pointer to one, and data is
+// an integer, a string parsed with base prefixes (0x, 0o, 0b) honored, or a big.Int. Float
+// sources are rejected. It reports false for any other combination.
+func (d *Decoder) assignBigInt(data reflect.Value, out reflect.Value) (bool, error) {
+	t := indirectType(out.Type())
+	if t != reflect.TypeFor[big.Int]() {
+		return false, nil
+	}
+
+	value := new(big.Int)
+	switch data.Kind() {
+	case reflect.String:
+		if _, ok := value.SetString(data.String(), 0); !ok {
+			return true, fmt.Errorf("invalid big.Int %q", data.String())
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt64(data.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value.SetUint64(data.Uint())
+	case reflect.Float32, reflect.Float64:
+		return true, fmt.Errorf("cannot decode %s into big.Int", data.Type())
+	case reflect.Struct:
+		if data.Type() != t {
+			return false, nil
+		}
+		src := data.Interface().(big.Int)
+		value.Set(&src)
+	default:
+		return false, nil
+	}
+	return true, d.setIndirect(out, reflect.ValueOf(value).Elem())
+}
+
+// assignBigFloat decodes data into out when out is a big.Float, or a pointer to one, and data
+// is a float other than NaN, an integer, a string or a big.Float. It reports false for any
+// other combination.
+func (d *Decoder) assignBigFloat(data reflect.Value, out reflect.Value) (bool, error) {
+	t := indirectType(out.Type())
+	if t != reflect.TypeFor[big.Float]() {
+		return false, nil
+	}
+
+	value := new(big.Float)
+	switch data.Kind() {
+	case reflect.String:
+		if _, ok := value.SetString(data.String()); !ok {
+			return true, fmt.Errorf("invalid big.Float %q", data.String())
+		}
+	case reflect.Float32, reflect.Float64:
+		if math.IsNaN(data.Float()) {
+			return true, errors.New("invalid big.Float NaN")
+		}
+		value.SetFloat64(data.Float())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt64(data.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value.SetUint64(data.Uint())
+	case reflect.Struct:
+		if data.Type() != t {
+			return false, nil
+		}
+		src := data.Interface().(big.Float)
+		value.Set(&src)
+	default:
+		return false, nil
+	}
+	return true, d.setIndirect(out, reflect.ValueOf(value).Elem())
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestBigIntField(t *testing.T) {
+	type Account struct {
+		Balance *big.Int
+		Nonce   big.Int
+	}
+
+	want, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+	tests := []struct {
+		name string
+		src  interface{}
+		want *big.Int
+	}{
+		{"decimal string", "123456789012345678901234567890", want},
+		{"hex string", "0x" + want.Text(16), want},
+		{"int", 42, big.NewInt(42)},
+		{"negative int64", int64(-7), big.NewInt(-7)},
+		{"uint64", uint64(1 << 63), new(big.Int).SetUint64(1 << 63)},
+		{"big.Int", want, want},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst Account
+			if err := i2s(map[string]interface{}{"Balance": tt.src, "Nonce": tt.src}, &dst); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if dst.Balance == nil || dst.Balance.Cmp(tt.want) != 0 {
+				t.Errorf("Balance = %v, want %v", dst.Balance, tt.want)
+			}
+			if dst.Nonce.Cmp(tt.want) != 0 {
+				t.Errorf("Nonce = %v, want %v", &dst.Nonce, tt.want)
+			}
+		})
+	}
+
+	for _, src := range []interface{}{"12abc", "", 1.5} {
+		var dst Account
+		if err := i2s(map[string]interface{}{"Balance": src}, &dst); err == nil {
+			t.Errorf("expected error for %#v", src)
+		}
+	}
+
+	t.Run("float source", func(t *testing.T) {
+		var dst Account
+		err := i2s(map[string]interface{}{"Balance": 3.0}, &dst)
+		if err == nil || !strings.Contains(err.Error(), "cannot decode float64 into big.Int") {
+			t.Errorf("expected float error, got %v", err)
+		}
+	})
+}
+
+func TestBigFloatField(t *testing.T) {
+	type Price struct {
+		Amount *big.Float
+	}
+
+	tests := []struct {
+		name string
+		src  interface{}
+		want float64
+	}{
+		{"string", "1234.5", 1234.5},
+		{"exponent string", "1.5e3", 1500},
+		{"float64", 0.25, 0.25},
+		{"int", 3, 3},
+		{"big.Float", big.NewFloat(2.5), 2.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst Price
+			if err := i2s(map[string]interface{}{"Amount": tt.src}, &dst); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if dst.Amount == nil || dst.Amount.Cmp(big.NewFloat(tt.want)) != 0 {
+				t.Errorf("Amount = %v, want %v", dst.Amount, tt.want)
+			}
+		})
+	}
+
+	var dst Price
+	if err := i2s(map[string]interface{}{"Amount": "one"}, &dst); err == nil {
+		t.Error("expected error for malformed string")
+	}
+	if err := i2s(map[string]interface{}{"Amount": math.NaN()}, &dst); err == nil {
+		t.Error("expected error for NaN")
+	}
+}
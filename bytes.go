@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// base64Encoding returns the encoding selected by a `base64` or `base64url` tag option,
+// honoring the `padding=none` modifier, or nil if the tag requests neither.
+func base64Encoding(tag fieldTag) (*base64.Encoding, error) {
+	var enc *base64.Encoding
+	switch {
+	case tag.has("base64"):
+		enc = base64.StdEncoding
+	case tag.has("base64url"):
+		enc = base64.URLEncoding
+	default:
+		return nil, nil
+	}
+
+	switch padding, _ := tag.value("padding"); padding {
+	case "":
+	case "none":
+		enc = enc.WithPadding(base64.NoPadding)
+	default:
+		return nil, fmt.Errorf("unsupported padding %q", padding)
+	}
+	return enc, nil
+}
+
+// decodeHex decodes a hexadecimal string with an optional 0x or 0X prefix.
+func decodeHex(s string) ([]byte, error) {
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	b, err := hex.DecodeString(trimmed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex string %q: %w", s, err)
+	}
+	return b, nil
+}
+
+// isByteContainer reports whether v is a []byte or a [N]byte.
+func isByteContainer(v reflect.Value) bool {
+	return checkIfArrayOrSlice(v) && v.Type().Elem().Kind() == reflect.Uint8
+}
+
+// isByteSlice reports whether v is a []byte.
+func isByteSlice(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8
+}
+
+// setBytes stores b in a []byte or [N]byte destination. Arrays must match len(b) exactly.
+func setBytes(dst reflect.Value, b []byte) error {
+	if dst.Kind() == reflect.Slice {
+		dst.SetBytes(b)
+		return nil
+	}
+	if dst.Len() != len(b) {
+		return fmt.Errorf("decoded %d bytes, destination array holds %d", len(b), dst.Len())
+	}
+	reflect.Copy(dst, reflect.ValueOf(b))
+	return nil
+}
+
+// assignStringBytes stores the string s in the []byte dst: decoded with the encoding set by
+// WithBase64Encoding, or as its UTF-8 bytes without one.
+func (d *Decoder) assignStringBytes(dst reflect.Value, s string) error {
+	if d.opts.Base64Encoding == nil {
+		dst.SetBytes([]byte(s))
+		return nil
+	}
+
+	b, err := d.opts.Base64Encoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("invalid base64 string: %w", err)
+	}
+	dst.SetBytes(b)
+	return nil
+}
+
+// decodeTaggedBytes decodes a string source into a []byte or [N]byte field according to the
+// field's `hex`, `base64` or `base64url` tag option. It reports whether the field was handled.
+func decodeTaggedBytes(field structField, value reflect.Value) (bool, error) {
+	src := dereferencePtr(value)
+	dst := field.value
+	if src.Kind() != reflect.String || !isByteContainer(dst) {
+		return false, nil
+	}
+
+	var b []byte
+	if field.tag.has("hex") {
+		var err error
+		if b, err = decodeHex(src.String()); err != nil {
+			return true, err
+		}
+	} else {
+		enc, err := base64Encoding(field.tag)
+		if err != nil {
+			return true, err
+		}
+		if enc == nil {
+			return false, nil
+		}
+		if b, err = enc.DecodeString(src.String()); err != nil {
+			return true, fmt.Errorf("invalid base64: %w", err)
+		}
+	}
+
+	if err := setBytes(dst, b); err != nil {
+		return true, err
+	}
+	return true, nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestBase64Tag(t *testing.T) {
+	type Blob struct {
+		Std    []byte `gomap:",base64"`
+		URL    []byte `gomap:",base64url"`
+		Raw    []byte `gomap:",base64,padding=none"`
+		RawURL []byte `gomap:",base64url,padding=none"`
+		Plain  []byte
+	}
+
+	payload := []byte{0xfb, 0xff, 0x01, 'h', 'i'}
+
+	t.Run("valid", func(t *testing.T) {
+		src := map[string]interface{}{
+			"Std":    "+/8BaGk=",
+			"URL":    "-_8BaGk=",
+			"Raw":    "+/8BaGk",
+			"RawURL": "-_8BaGk",
+			"Plain":  "hello",
+		}
+		var dst Blob
+		if err := i2s(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for name, got := range map[string][]byte{"Std": dst.Std, "URL": dst.URL, "Raw": dst.Raw, "RawURL": dst.RawURL} {
+			if !bytes.Equal(got, payload) {
+				t.Errorf("%s = %v, want %v", name, got, payload)
+			}
+		}
+		if string(dst.Plain) != "hello" {
+			t.Errorf("expected UTF-8 bytes without tag, got %q", dst.Plain)
+		}
+	})
+
+	tests := []struct {
+		name string
+		src  map[string]interface{}
+	}{
+		{"invalid characters", map[string]interface{}{"Std": "not base64!"}},
+		{"url alphabet in std field", map[string]interface{}{"Std": "-_8BaGk="}},
+		{"padding in unpadded field", map[string]interface{}{"Raw": "+/8BaGk="}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst Blob
+			err := i2s(tt.src, &dst)
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("expected DecodeError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHexTag(t *testing.T) {
+	type Key struct {
+		Data  []byte  `gomap:",hex"`
+		Fixed [4]byte `gomap:",hex"`
+	}
+
+	want := []byte{0xde, 0xad, 0xbe, 0xef}
+
+	valid := []struct {
+		name string
+		src  string
+	}{
+		{"lowercase", "deadbeef"},
+		{"uppercase", "DEADBEEF"},
+		{"0x prefix", "0xdeadbeef"},
+		{"0X prefix", "0XDEADBEEF"},
+	}
+	for _, tt := range valid {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst Key
+			if err := i2s(map[string]interface{}{"Data": tt.src, "Fixed": tt.src}, &dst); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(dst.Data, want) || !bytes.Equal(dst.Fixed[:], want) {
+				t.Errorf("unexpected result: %x %x", dst.Data, dst.Fixed)
+			}
+		})
+	}
+
+	invalid := []struct {
+		name string
+		src  map[string]interface{}
+	}{
+		{"odd length", map[string]interface{}{"Data": "abc"}},
+		{"non-hex characters", map[string]interface{}{"Data": "zz"}},
+		{"array length mismatch", map[string]interface{}{"Fixed": "dead"}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst Key
+			err := i2s(tt.src, &dst)
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("expected DecodeError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBase64EncodingOption(t *testing.T) {
+	type Blob struct {
+		Data []byte
+	}
+
+	payload := []byte{0xfb, 0xff, 0x01, 'h', 'i'}
+
+	encodings := []struct {
+		name string
+		enc  *base64.Encoding
+	}{
+		{"std", base64.StdEncoding},
+		{"url", base64.URLEncoding},
+		{"raw std", base64.RawStdEncoding},
+		{"raw url", base64.RawURLEncoding},
+	}
+	for _, tt := range encodings {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst Blob
+			src := map[string]interface{}{"Data": tt.enc.EncodeToString(payload)}
+			if err := NewDecoder(WithBase64Encoding(tt.enc)).Decode(src, &dst); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(dst.Data, payload) {
+				t.Errorf("Data = %v, want %v", dst.Data, payload)
+			}
+		})
+	}
+
+	t.Run("corrupt", func(t *testing.T) {
+		var dst Blob
+		err := NewDecoder(WithBase64Encoding(base64.StdEncoding)).Decode(map[string]interface{}{"Data": "not base64!"}, &dst)
+		var corrupt base64.CorruptInputError
+		if !errors.As(err, &corrupt) {
+			t.Fatalf("expected CorruptInputError, got %v", err)
+		}
+	})
+
+	t.Run("byte slice source is copied", func(t *testing.T) {
+		var dst Blob
+		src := []byte("raw")
+		d := NewDecoder(WithBase64Encoding(base64.StdEncoding))
+		if err := d.Decode(map[string]interface{}{"Data": src}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		src[0] = 'R'
+		if string(dst.Data) != "raw" {
+			t.Errorf("Data = %q, want an independent copy of \"raw\"", dst.Data)
+		}
+	})
+}
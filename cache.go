@@ -0,0 +1,218 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// structMeta is the cached description of how source keys map to the fields of a struct type.
+type structMeta struct {
+	// fields maps effective names to the fields that are alone in using them.
+	fields map[string]fieldMeta
+	// grouped maps effective names shared by several fields, or used by a versioned field, to
+	// those fields in declaration order. Resolving them depends on the decoder's options.
+	grouped map[string][]fieldMeta
+	// preDecoder and postDecoder report whether pointers to the struct implement PreDecoder
+	// and PostDecoder.
+	preDecoder, postDecoder bool
+	// required reports whether any field is tagged `required`.
+	required bool
+	// defaults reports whether any field has a `default` tag option.
+	defaults bool
+	// positional reports whether any field has the `pos` tag option, which makes slices and
+	// arrays decode into the struct by position.
+	positional bool
+	// formNames maps the names given by `form` tags to the effective names of their fields.
+	formNames map[string]string
+	// remain holds the fields tagged `remain`, which take the keys no other field matches
+	// instead of being decoded by name, see splitRemain.
+	remain []fieldMeta
+}
+
+// fieldMeta describes a struct field independently of any value of the struct.
+type fieldMeta struct {
+	// index is the field's index sequence, as for reflect.Value.FieldByIndex.
+	index []int
+	// name is the Go field name, prefixed with the embedded fields it is promoted through.
+	name string
+	tag  fieldTag
+}
+
+// structMetas maps struct types to their *structMeta for the default tag key.
+var structMetas sync.Map //nolint:gochecknoglobals // cache shared by every Decoder
+
+// tagKeyMetas maps custom tag keys to the *sync.Map caching struct metadata for that key.
+var tagKeyMetas sync.Map //nolint:gochecknoglobals // caches shared by every Decoder
+
+// metaCacheFor returns the metadata cache for struct tags read from tagKey.
+func metaCacheFor(tagKey string) *sync.Map {
+	if tagKey == defaultTagKey {
+		return &structMetas
+	}
+	cache, _ := tagKeyMetas.LoadOrStore(tagKey, new(sync.Map))
+	return cache.(*sync.Map)
+}
+
+// structMeta returns the metadata of the struct type t, computing it on first use.
+func (d *Decoder) structMeta(t reflect.Type) *structMeta {
+	if meta, ok := d.metas.Load(t); ok {
+		return meta.(*structMeta)
+	}
+	meta, _ := d.metas.LoadOrStore(t, buildStructMeta(t, d.opts.tagKey()))
+	return meta.(*structMeta)
+}
+
+// buildStructMeta computes the metadata of the struct type t, reading tags from tagKey.
+// Unexported fields and fields tagged `gomap:"-"` are left out. The fields of untagged embedded
+// structs, of struct fields tagged `squash`, and of pointers to them, are promoted; they share
+// names with outer fields as they do in Go, and resolveCollision later picks the least nested
+// one. Fields tagged `remain` are kept apart, see splitRemain.
+func buildStructMeta(t reflect.Type, tagKey string) *structMeta {
+	meta := &structMeta{fields: make(map[string]fieldMeta, t.NumField())}
+	meta.add(t, tagKey, nil, "", map[reflect.Type]bool{t: true})
+	meta.preDecoder = reflect.PointerTo(t).Implements(reflect.TypeFor[PreDecoder]())
+	meta.postDecoder = reflect.PointerTo(t).Implements(reflect.TypeFor[PostDecoder]())
+	return meta
+}
+
+// add records the fields of the struct type t, reached through the index sequence index and
+// the field name prefix. seen holds the struct types being walked, to stop embedding cycles.
+func (m *structMeta) add(t reflect.Type, tagKey string, index []int, prefix string, seen map[reflect.Type]bool) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := parseFieldTag(field.Tag.Get(tagKey))
+		if tag.ignored() {
+			continue
+		}
+
+		fieldIndex := append(slices.Clip(index), i)
+		if embedded, ok := promotedStruct(field, tag); ok {
+			if !seen[embedded] {
+				seen[embedded] = true
+				m.add(embedded, tagKey, fieldIndex, prefix+field.Name+".", seen)
+				delete(seen, embedded)
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if tag.has("remain") {
+			m.remain = append(m.remain, fieldMeta{index: fieldIndex, name: prefix + field.Name, tag: tag})
+			continue
+		}
+		name := field.Name
+		if tag.name != "" {
+			name = tag.name
+		}
+		if alias, _, _ := strings.Cut(field.Tag.Get(formTagKey), ","); alias != "" && alias != "-" && alias != name {
+			if m.formNames == nil {
+				m.formNames = make(map[string]string)
+			}
+			m.formNames[alias] = name
+		}
+		m.positional = m.positional || tag.has("pos")
+		m.required = m.required || tag.has("required")
+		m.defaults = m.defaults || tag.has("default")
+		m.addField(name, fieldMeta{index: fieldIndex, name: prefix + field.Name, tag: tag})
+	}
+}
+
+// promotedStruct returns the struct type whose fields the embedded field promotes. Embedded
+// fields with a tag name are decoded as regular fields, and unexported embedded pointers are
+// skipped since they cannot be allocated. Fields tagged `squash` are promoted whether they are
+// embedded or not, as long as they are exported.
+func promotedStruct(field reflect.StructField, tag fieldTag) (reflect.Type, bool) {
+	if tag.has("squash") {
+		if !field.IsExported() {
+			return nil, false
+		}
+	} else if !field.Anonymous || tag.name != "" {
+		return nil, false
+	}
+
+	t := field.Type
+	if t.Kind() == reflect.Pointer {
+		if !field.IsExported() {
+			return nil, false
+		}
+		t = t.Elem()
+	}
+	return t, t.Kind() == reflect.Struct
+}
+
+// addField records fm under the effective name. Names used by several fields, or by
+// versioned fields, are grouped for resolution by the decoder.
+func (m *structMeta) addField(name string, fm fieldMeta) {
+	prev, dup := m.fields[name]
+	if !dup && !fm.tag.has("version") && m.grouped[name] == nil {
+		m.fields[name] = fm
+		return
+	}
+	if m.grouped == nil {
+		m.grouped = make(map[string][]fieldMeta)
+	}
+	if dup {
+		m.grouped[name] = append(m.grouped[name], prev)
+		delete(m.fields, name)
+	}
+	m.grouped[name] = append(m.grouped[name], fm)
+}
+
+// field returns the field of the struct value out described by m. The value of a field
+// promoted through a nil embedded pointer is invalid: looking fields up never modifies out,
+// and the pointers are only allocated by structField.allocate when the field is assigned.
+func (m fieldMeta) field(out reflect.Value) structField {
+	field := structField{name: m.name, tag: m.tag, depth: len(m.index) - 1, owner: out, index: m.index}
+	v := out.Field(m.index[0])
+	for _, i := range m.index[1:] {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return field
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	field.value = v
+	return field
+}
+
+// allocate makes the value of a field promoted through nil embedded pointers valid by
+// allocating them, which requires its owner to be settable. The value stays invalid otherwise.
+func (f *structField) allocate() {
+	if f.value.IsValid() || !f.owner.IsValid() || !f.owner.CanSet() {
+		return
+	}
+	v := f.owner.Field(f.index[0])
+	for _, i := range f.index[1:] {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	f.value = v
+}
+
+// typ returns the type of the field, also while its value is invalid.
+func (f structField) typ() reflect.Type {
+	if f.value.IsValid() || !f.owner.IsValid() {
+		return f.value.Type()
+	}
+	return f.owner.Type().FieldByIndex(f.index).Type
+}
+
+// structFields returns the ungrouped fields of the struct value out keyed by effective name.
+func (m *structMeta) structFields(out reflect.Value) map[string]structField {
+	fields := make(map[string]structField, len(m.fields))
+	for name, fm := range m.fields {
+		fields[name] = fm.field(out)
+	}
+	return fields
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// FastDecoderFunc decodes src into out, a pointer to the struct type it was registered for.
+type FastDecoderFunc func(src map[string]interface{}, out interface{}) error
+
+// fastDecoders maps struct types to the reflection-free decoders registered for them.
+var fastDecoders sync.Map //nolint:gochecknoglobals // registry shared by every call to MapToStruct
+
+// RegisterFastDecoder registers fn as the decoder MapToStruct uses for sources decoded into a
+// *t, bypassing reflection. fn is typically produced by GenerateDecoder, whose documentation
+// lists the conversions such decoders do not perform. The registry is consulted only while the
+// default options leave the fast path enabled.
+func RegisterFastDecoder(t reflect.Type, fn func(map[string]interface{}, interface{}) error) {
+	fastDecoders.Store(t, FastDecoderFunc(fn))
+}
+
+// fastDecoderFor returns the fast decoder registered for the struct out points to, if any.
+func fastDecoderFor(out interface{}) (FastDecoderFunc, bool) {
+	t := reflect.TypeOf(out)
+	if t == nil || t.Kind() != reflect.Pointer {
+		return nil, false
+	}
+	fn, ok := fastDecoders.Load(t.Elem())
+	if !ok {
+		return nil, false
+	}
+	decoder, ok := fn.(FastDecoderFunc)
+	return decoder, ok
+}
+
+// GenerateDecoder returns the Go source of a reflection-free function named funcName, in
+// package packageName, that decodes a map[string]interface{} into the struct type of proto.
+// Integer and float fields accept any integer or float value and convert it as MapToStruct
+// does, rejecting fractional parts and values out of range; string and bool fields accept
+// values of their underlying type. Any other field is assigned with a type assertion, so the
+// source value must already have the field's type: nested structs, slices and maps are not
+// converted, and the fields of embedded structs are not promoted. Tag options other than the
+// name and `-`/decode_ignore, such as `default` and `required`, are ignored.
+//
+// The struct must be declared in packageName, and its exported fields must have predeclared
+// or same-package types. A typical go:generate workflow is a small program that calls
+// GenerateDecoder and writes the result next to the struct:
+//
+//	//go:generate go run ./gen -type User -out user_decoder.go
+//
+// and registers the generated function so that MapToStruct picks it up:
+//
+//	func init() { gostructmap.RegisterFastDecoder(reflect.TypeFor[User](), DecodeUser) }
+func GenerateDecoder(proto interface{}, packageName, funcName string) (string, error) {
+	t := reflect.TypeOf(proto)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || t.Name() == "" {
+		return "", fmt.Errorf("expected named struct, got %T", proto)
+	}
+
+	// helpers holds the numeric conversion helpers, named after funcName so that several
+	// generated decoders can share a package.
+	helpers := strings.ToLower(funcName[:1]) + funcName[1:]
+	var body bytes.Buffer
+	numeric := false
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := parseFieldTag(field.Tag.Get(defaultTagKey))
+		if !field.IsExported() || tag.skipDecode() {
+			continue
+		}
+
+		typeName, err := generatedTypeName(field.Type, t.PkgPath())
+		if err != nil {
+			return "", fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		key := field.Name
+		if tag.name != "" {
+			key = tag.name
+		}
+
+		fmt.Fprintf(&body, "if v, ok := src[%q]; ok && v != nil {\n", key)
+		if conv, ok := generatedNumericConversion(field.Type, helpers); ok {
+			numeric = true
+			fmt.Fprintf(&body, "x, err := %s\nif err != nil {\n", conv)
+			fmt.Fprintf(&body, "return fmt.Errorf(\"field %%q: %%w\", %q, err)\n}\n", key)
+			fmt.Fprintf(&body, "dst.%s = %s(x)\n}\n", field.Name, typeName)
+			continue
+		}
+		assertType := typeName
+		if k := field.Type.Kind(); k == reflect.String || k == reflect.Bool {
+			assertType = k.String()
+		}
+		fmt.Fprintf(&body, "x, ok := v.(%s)\nif !ok {\n", assertType)
+		fmt.Fprintf(&body, "return fmt.Errorf(\"field %%q: expected %s, got %%T\", %q, v)\n}\n", assertType, key)
+		if assertType == typeName {
+			fmt.Fprintf(&body, "dst.%s = x\n}\n", field.Name)
+		} else {
+			fmt.Fprintf(&body, "dst.%s = %s(x)\n}\n", field.Name, typeName)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gostructmap.GenerateDecoder; DO NOT EDIT.\n\n")
+	if numeric {
+		fmt.Fprintf(&buf, "package %s\n\nimport (\n\"fmt\"\n\"math\"\n)\n\n", packageName)
+	} else {
+		fmt.Fprintf(&buf, "package %s\n\nimport \"fmt\"\n\n", packageName)
+	}
+	fmt.Fprintf(&buf, "// %s decodes src into out, which must be a *%s.\n", funcName, t.Name())
+	fmt.Fprintf(&buf, "func %s(src map[string]interface{}, out interface{}) error {\n", funcName)
+	fmt.Fprintf(&buf, "dst, ok := out.(*%s)\nif !ok {\n", t.Name())
+	fmt.Fprintf(&buf, "return fmt.Errorf(\"%s: expected *%s, got %%T\", out)\n}\n", funcName, t.Name())
+	buf.Write(body.Bytes())
+	fmt.Fprintf(&buf, "return nil\n}\n")
+	if numeric {
+		fmt.Fprintf(&buf, generatedNumericHelpers, helpers)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("formatting generated code: %w", err)
+	}
+	return string(src), nil
+}
+
+// generatedNumericConversion returns the call converting the source value v for a field of the
+// integer or float type t, using the helpers of generatedNumericHelpers prefixed with helpers.
+// It reports false for other types.
+func generatedNumericConversion(t reflect.Type, helpers string) (string, bool) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bound := strings.TrimPrefix(t.Kind().String(), "int")
+		return fmt.Sprintf("%sInt(v, math.MinInt%s, math.MaxInt%s)", helpers, bound, bound), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		bound := strings.TrimPrefix(t.Kind().String(), "uint")
+		return fmt.Sprintf("%sUint(v, math.MaxUint%s)", helpers, bound), true
+	case reflect.Float32, reflect.Float64:
+		return fmt.Sprintf("%sFloat(v, %d)", helpers, t.Bits()), true
+	default:
+		return "", false
+	}
+}
+
+// generatedNumericHelpers is the source of the conversion helpers used by generated decoders
+// with integer or float fields, formatted with the helpers' name prefix. They follow the checks
+// of setInt, setUint, setIntFromFloat, setUintFromFloat and setFloat.
+const generatedNumericHelpers = `
+// %[1]sInt converts the integer or float v to an int64 within [lo, hi].
+func %[1]sInt(v interface{}, lo, hi int64) (int64, error) {
+	var n int64
+	switch x := v.(type) {
+	case int:
+		n = int64(x)
+	case int8:
+		n = int64(x)
+	case int16:
+		n = int64(x)
+	case int32:
+		n = int64(x)
+	case int64:
+		n = x
+	case uint, uint8, uint16, uint32, uint64:
+		u, err := %[1]sUint(x, math.MaxInt64)
+		if err != nil {
+			return 0, err
+		}
+		n = int64(u)
+	case float32, float64:
+		f, _ := %[1]sFloat(x, 64)
+		if math.Trunc(f) != f {
+			return 0, fmt.Errorf("value %%v has a fractional part", f)
+		}
+		// float64(math.MaxInt64) rounds up to 2^63, which no longer fits.
+		if f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, fmt.Errorf("value %%v overflows int64", f)
+		}
+		n = int64(f)
+	default:
+		return 0, fmt.Errorf("expected integer or float, got %%T", v)
+	}
+	if n < lo || n > hi {
+		return 0, fmt.Errorf("value %%d out of range", n)
+	}
+	return n, nil
+}
+
+// %[1]sUint converts the non-negative integer or float v to a uint64 no greater than hi.
+func %[1]sUint(v interface{}, hi uint64) (uint64, error) {
+	var n uint64
+	switch x := v.(type) {
+	case uint:
+		n = uint64(x)
+	case uint8:
+		n = uint64(x)
+	case uint16:
+		n = uint64(x)
+	case uint32:
+		n = uint64(x)
+	case uint64:
+		n = x
+	case int, int8, int16, int32, int64:
+		i, err := %[1]sInt(x, math.MinInt64, math.MaxInt64)
+		if err != nil {
+			return 0, err
+		}
+		if i < 0 {
+			return 0, fmt.Errorf("value %%d is negative", i)
+		}
+		n = uint64(i)
+	case float32, float64:
+		f, _ := %[1]sFloat(x, 64)
+		if math.Trunc(f) != f {
+			return 0, fmt.Errorf("value %%v has a fractional part", f)
+		}
+		if f < 0 || f >= math.MaxUint64 {
+			return 0, fmt.Errorf("value %%v out of range", f)
+		}
+		n = uint64(f)
+	default:
+		return 0, fmt.Errorf("expected integer or float, got %%T", v)
+	}
+	if n > hi {
+		return 0, fmt.Errorf("value %%d out of range", n)
+	}
+	return n, nil
+}
+
+// %[1]sFloat converts the integer or float v to a float64 that fits a float of the given bits.
+func %[1]sFloat(v interface{}, bits int) (float64, error) {
+	var f float64
+	switch x := v.(type) {
+	case float32:
+		f = float64(x)
+	case float64:
+		f = x
+	case int, int8, int16, int32, int64:
+		i, err := %[1]sInt(x, math.MinInt64, math.MaxInt64)
+		return float64(i), err
+	case uint, uint8, uint16, uint32, uint64:
+		u, err := %[1]sUint(x, math.MaxUint64)
+		return float64(u), err
+	default:
+		return 0, fmt.Errorf("expected integer or float, got %%T", v)
+	}
+	if bits == 32 && math.Abs(f) > math.MaxFloat32 && !math.IsInf(f, 0) {
+		return 0, fmt.Errorf("value %%v overflows float32", f)
+	}
+	return f, nil
+}
+`
+
+// generatedTypeName returns how t is spelled in generated code living in package pkgPath.
+func generatedTypeName(t reflect.Type, pkgPath string) (string, error) {
+	switch {
+	case t.Name() != "" && t.PkgPath() == pkgPath:
+		return t.Name(), nil
+	case t.PkgPath() == "" && !strings.Contains(t.String(), "."):
+		return t.String(), nil
+	default:
+		return "", fmt.Errorf("unsupported type %s", t)
+	}
+}
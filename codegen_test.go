@@ -0,0 +1,192 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type generatedUser struct {
+	ID     int `gomap:"id"`
+	Name   string
+	Tags   []string
+	Skip   string `gomap:"-"`
+	hidden int
+}
+
+func TestGenerateDecoder(t *testing.T) {
+	src, err := GenerateDecoder(generatedUser{}, "users", "DecodeUser")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "user_decoder.go", src, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, src)
+	}
+
+	for _, want := range []string{
+		"package users",
+		"func DecodeUser(src map[string]interface{}, out interface{}) error",
+		"dst, ok := out.(*generatedUser)",
+		`src["id"]`,
+		`x, err := decodeUserInt(v, math.MinInt, math.MaxInt)`,
+		"dst.ID = int(x)",
+		"x, ok := v.([]string)",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated code is missing %q:\n%s", want, src)
+		}
+	}
+	if strings.Contains(src, "Skip") || strings.Contains(src, "hidden") {
+		t.Errorf("generated code decodes skipped fields:\n%s", src)
+	}
+
+	t.Run("unsupported field type", func(t *testing.T) {
+		type withForeign struct {
+			Timeout time.Duration
+		}
+		if _, err := GenerateDecoder(withForeign{}, "p", "Decode"); err == nil {
+			t.Error("expected error for field type from another package")
+		}
+	})
+
+	t.Run("non-struct proto", func(t *testing.T) {
+		if _, err := GenerateDecoder(42, "p", "Decode"); err == nil {
+			t.Error("expected error for non-struct proto")
+		}
+	})
+}
+
+type generatedLevel string
+
+type generatedNumbers struct {
+	Count int
+	Small int8
+	Port  uint16
+	Ratio float32
+	Score float64
+	Level generatedLevel
+}
+
+// generatedNumbersSource declares generatedNumbers for the program run by
+// TestGenerateDecoderMatchesI2S; keep it in sync with the declarations above.
+const generatedNumbersSource = `package main
+
+import "fmt"
+
+type generatedLevel string
+
+type generatedNumbers struct {
+	Count int
+	Small int8
+	Port  uint16
+	Ratio float32
+	Score float64
+	Level generatedLevel
+}
+
+func main() {
+	for _, src := range inputs {
+		var dst generatedNumbers
+		if err := decodeNumbers(src, &dst); err != nil {
+			fmt.Println("error")
+			continue
+		}
+		fmt.Printf("%+v\n", dst)
+	}
+}
+`
+
+func TestGenerateDecoderMatchesI2S(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs the generated decoder")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not available")
+	}
+
+	inputs := []map[string]interface{}{
+		{"Count": 3.0, "Small": -5.0, "Port": 8080.0, "Ratio": 0.5, "Score": 7.25, "Level": "high"},
+		{"Count": int64(3), "Small": uint8(5), "Port": 80, "Ratio": 2, "Score": uint(7)},
+		{"Count": 1.5},
+		{"Small": 300.0},
+		{"Port": -1.0},
+		{"Ratio": 1e300},
+		{"Count": "3"},
+	}
+
+	src, err := GenerateDecoder(generatedNumbers{}, "main", "decodeNumbers")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var literal strings.Builder
+	literal.WriteString("package main\n\nvar inputs = []map[string]interface{}{\n")
+	var want strings.Builder
+	for _, in := range inputs {
+		literal.WriteString("{")
+		for k, v := range in {
+			fmt.Fprintf(&literal, "%q: %T(%#v), ", k, v, v)
+		}
+		literal.WriteString("},\n")
+
+		var dst generatedNumbers
+		if err := i2s(in, &dst); err != nil {
+			want.WriteString("error\n")
+			continue
+		}
+		fmt.Fprintf(&want, "%+v\n", dst)
+	}
+	literal.WriteString("}\n")
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod":     "module generated\n\ngo 1.21\n",
+		"main.go":    generatedNumbersSource,
+		"decoder.go": src,
+		"inputs.go":  literal.String(),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command(goTool, "run", ".")
+	cmd.Dir = dir
+	got, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("running generated decoder: %v\n%s\n%s", err, got, src)
+	}
+	if string(got) != want.String() {
+		t.Errorf("generated decoder output:\n%s\ni2s output:\n%s", got, want.String())
+	}
+}
+
+func TestRegisterFastDecoder(t *testing.T) {
+	type fastTarget struct {
+		Value int
+	}
+
+	errCalled := errors.New("fast decoder called")
+	RegisterFastDecoder(reflect.TypeFor[fastTarget](), func(src map[string]interface{}, out interface{}) error {
+		out.(*fastTarget).Value = src["Value"].(int) * 2
+		return errCalled
+	})
+	t.Cleanup(func() { fastDecoders.Delete(reflect.TypeFor[fastTarget]()) })
+
+	var dst fastTarget
+	if err := i2s(map[string]interface{}{"Value": 2}, &dst); !errors.Is(err, errCalled) {
+		t.Fatalf("expected registered decoder to be used, got %v", err)
+	}
+	if dst.Value != 4 {
+		t.Errorf("expected 4, got %d", dst.Value)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// CoercionFunc converts a source value into a value assignable to a destination kind.
+type CoercionFunc func(reflect.Value) (reflect.Value, error)
+
+// coercionKey identifies a source/destination kind pair in a CoercionRegistry.
+type coercionKey struct {
+	from reflect.Kind
+	to   reflect.Kind
+}
+
+// CoercionRegistry holds kind-level conversions that take precedence over the
+// built-in conversions of assignSimpleValue.
+type CoercionRegistry struct {
+	coercions map[coercionKey]CoercionFunc
+}
+
+// NewCoercionRegistry creates an empty CoercionRegistry.
+func NewCoercionRegistry() *CoercionRegistry {
+	return &CoercionRegistry{coercions: make(map[coercionKey]CoercionFunc)}
+}
+
+// Register sets the conversion used when a value of kind `from` is assigned to a
+// destination of kind `to`. A later registration for the same pair replaces the earlier one.
+// A conversion registered for reflect.Int64, reflect.Uint64 or reflect.Float64 also applies to
+// the other integer, unsigned integer or float destination kinds respectively, unless a
+// conversion is registered for the exact pair.
+func (r *CoercionRegistry) Register(from, to reflect.Kind, fn func(reflect.Value) (reflect.Value, error)) {
+	if r.coercions == nil {
+		r.coercions = make(map[coercionKey]CoercionFunc)
+	}
+	r.coercions[coercionKey{from: from, to: to}] = fn
+}
+
+// lookup returns the conversion registered for the kind pair, if any, falling back to the one
+// registered for the widest kind of the destination's numeric family.
+func (r *CoercionRegistry) lookup(from, to reflect.Kind) (CoercionFunc, bool) {
+	if r == nil {
+		return nil, false
+	}
+	if fn, ok := r.coercions[coercionKey{from: from, to: to}]; ok {
+		return fn, true
+	}
+	widest := numericFamily(to)
+	if widest == reflect.Invalid || widest == to {
+		return nil, false
+	}
+	fn, ok := r.coercions[coercionKey{from: from, to: widest}]
+	return fn, ok
+}
+
+// numericFamily returns the widest kind of the integer, unsigned integer or float family of k,
+// or reflect.Invalid if k is not one of them.
+func numericFamily(k reflect.Kind) reflect.Kind {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.Int64
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return reflect.Uint64
+	case reflect.Float32, reflect.Float64:
+		return reflect.Float64
+	default:
+		return reflect.Invalid
+	}
+}
+
+// applyCoercion runs fn on src and stores the result in dst. Numeric results are stored with
+// the overflow checks of the built-in conversions; other results are converted to the
+// destination type when they are not directly assignable.
+func (d *Decoder) applyCoercion(fn CoercionFunc, dst reflect.Value, src reflect.Value) error {
+	res, err := fn(src)
+	if err != nil {
+		return fmt.Errorf("coercion from %s to %s failed: %w", src.Kind(), dst.Kind(), err)
+	}
+	if !res.IsValid() {
+		return fmt.Errorf("coercion from %s to %s returned no value", src.Kind(), dst.Kind())
+	}
+
+	if handled, err := d.setNumeric(dst, res); handled {
+		return err
+	}
+	switch {
+	case res.Type().AssignableTo(dst.Type()):
+		dst.Set(res)
+	case res.Type().ConvertibleTo(dst.Type()):
+		dst.Set(res.Convert(dst.Type()))
+	default:
+		return fmt.Errorf("coercion returned %s, not assignable to %s", res.Type(), dst.Type())
+	}
+	return nil
+}
+
+// setNumeric stores the integer or float v in the integer or float dst with setInt, setUint,
+// setIntFromFloat, setUintFromFloat or setFloat. It reports false if either is not numeric.
+func (d *Decoder) setNumeric(dst reflect.Value, v reflect.Value) (bool, error) {
+	from, to := numericFamily(v.Kind()), numericFamily(dst.Kind())
+	if from == reflect.Invalid || to == reflect.Invalid {
+		return false, nil
+	}
+
+	switch to {
+	case reflect.Int64:
+		switch from {
+		case reflect.Int64:
+			return true, d.setInt(dst, v.Int())
+		case reflect.Uint64:
+			if v.Uint() > math.MaxInt64 {
+				return true, fmt.Errorf("value %d overflows %s", v.Uint(), dst.Type())
+			}
+			return true, d.setInt(dst, int64(v.Uint()))
+		default:
+			return true, d.setIntFromFloat(dst, v.Float())
+		}
+	case reflect.Uint64:
+		switch from {
+		case reflect.Int64:
+			if v.Int() < 0 {
+				return true, fmt.Errorf("value %d overflows %s", v.Int(), dst.Type())
+			}
+			return true, d.setUint(dst, uint64(v.Int()))
+		case reflect.Uint64:
+			return true, d.setUint(dst, v.Uint())
+		default:
+			return true, d.setUintFromFloat(dst, v.Float())
+		}
+	default:
+		switch from {
+		case reflect.Int64:
+			return true, d.setFloat(dst, float64(v.Int()))
+		case reflect.Uint64:
+			return true, d.setFloat(dst, float64(v.Uint()))
+		default:
+			return true, d.setFloat(dst, v.Float())
+		}
+	}
+}
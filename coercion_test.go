@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"hash/fnv"
+	"reflect"
+	"testing"
+)
+
+func TestCoercionRegistry(t *testing.T) {
+	type Hashed struct {
+		I   int
+		I8  int8
+		I16 int16
+		I32 int32
+		I64 int64
+		S   string
+	}
+
+	calls := 0
+	hash := func(src reflect.Value) (reflect.Value, error) {
+		calls++
+		h := fnv.New32a()
+		h.Write([]byte(src.String()))
+		return reflect.ValueOf(int64(h.Sum32() % 100)), nil
+	}
+
+	registry := NewCoercionRegistry()
+	registry.Register(reflect.String, reflect.Int64, hash)
+
+	t.Run("string to any int", func(t *testing.T) {
+		calls = 0
+		src := map[string]interface{}{
+			"I": "a", "I8": "a", "I16": "a", "I32": "a", "I64": "a", "S": "a",
+		}
+		var dst Hashed
+		if err := NewDecoder(WithCoercionRegistry(registry)).Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if calls != 5 {
+			t.Errorf("expected coercion to be called 5 times, got %d", calls)
+		}
+		want, _ := hash(reflect.ValueOf("a"))
+		n := want.Int()
+		if int64(dst.I) != n || int64(dst.I8) != n || int64(dst.I16) != n || int64(dst.I32) != n || dst.I64 != n {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+		if dst.S != "a" {
+			t.Errorf("expected string field to be untouched by coercion, got %q", dst.S)
+		}
+	})
+
+	t.Run("built-in conversions still apply", func(t *testing.T) {
+		src := map[string]interface{}{"I": 3.0}
+		var dst Hashed
+		if err := NewDecoder(WithCoercionRegistry(registry)).Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.I != 3 {
+			t.Errorf("expected 3, got %d", dst.I)
+		}
+	})
+
+	t.Run("coercion error", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		r := NewCoercionRegistry()
+		r.Register(reflect.String, reflect.Int, func(reflect.Value) (reflect.Value, error) {
+			return reflect.Value{}, errBoom
+		})
+		var dst Hashed
+		err := NewDecoder(WithCoercionRegistry(r)).Decode(map[string]interface{}{"I": "x"}, &dst)
+		if !errors.Is(err, errBoom) {
+			t.Errorf("expected wrapped coercion error, got %v", err)
+		}
+	})
+
+	t.Run("exact pair takes precedence", func(t *testing.T) {
+		r := NewCoercionRegistry()
+		r.Register(reflect.String, reflect.Int64, hash)
+		r.Register(reflect.String, reflect.Int8, func(reflect.Value) (reflect.Value, error) {
+			return reflect.ValueOf(-1), nil
+		})
+		var dst Hashed
+		if err := NewDecoder(WithCoercionRegistry(r)).Decode(map[string]interface{}{"I8": "a"}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.I8 != -1 {
+			t.Errorf("expected -1, got %d", dst.I8)
+		}
+	})
+
+	t.Run("overflowing result", func(t *testing.T) {
+		r := NewCoercionRegistry()
+		r.Register(reflect.String, reflect.Int64, func(reflect.Value) (reflect.Value, error) {
+			return reflect.ValueOf(int64(1000)), nil
+		})
+		var dst Hashed
+		if err := NewDecoder(WithCoercionRegistry(r)).Decode(map[string]interface{}{"I8": "x"}, &dst); err == nil {
+			t.Errorf("expected overflow error, got %d", dst.I8)
+		}
+		truncating := NewDecoder(WithCoercionRegistry(r), WithAllowTruncate())
+		if err := truncating.Decode(map[string]interface{}{"I8": "x"}, &dst); err != nil {
+			t.Errorf("unexpected error with truncation allowed: %v", err)
+		}
+	})
+
+	t.Run("invalid result", func(t *testing.T) {
+		r := NewCoercionRegistry()
+		r.Register(reflect.String, reflect.Int, func(reflect.Value) (reflect.Value, error) {
+			return reflect.Value{}, nil
+		})
+		var dst Hashed
+		if err := NewDecoder(WithCoercionRegistry(r)).Decode(map[string]interface{}{"I": "x"}, &dst); err == nil {
+			t.Error("expected error for missing coercion result")
+		}
+	})
+
+	t.Run("incompatible result", func(t *testing.T) {
+		r := NewCoercionRegistry()
+		r.Register(reflect.String, reflect.Int, func(reflect.Value) (reflect.Value, error) {
+			return reflect.ValueOf([]int{1}), nil
+		})
+		var dst Hashed
+		err := NewDecoder(WithCoercionRegistry(r)).Decode(map[string]interface{}{"I": "x"}, &dst)
+		if err == nil {
+			t.Error("expected error for non-assignable coercion result")
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package main
+
+import "context"
+
+// DecodeContext is Decode bounded by ctx: decoding stops with an error wrapping ctx.Err() as
+// soon as ctx is cancelled or its deadline passes, even with WithCollectErrors. This keeps
+// large or deeply nested inputs from outliving, for instance, the request they came with.
+func (d *Decoder) DecodeContext(ctx context.Context, data interface{}, out interface{}) error {
+	s := d.session()
+	defer d.release(s)
+	s.ctx = ctx
+	return d.wrapError(out, s.decode(data, out))
+}
+
+// ctxErr returns the error of the context the session runs under, if it is done.
+func (d *Decoder) ctxErr() error {
+	if d.ctx == nil {
+		return nil
+	}
+	return d.ctx.Err()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeContext(t *testing.T) {
+	type Item struct {
+		ID int
+	}
+	type List struct {
+		Items []Item
+	}
+	src := map[string]interface{}{"Items": []interface{}{
+		map[string]interface{}{"ID": 1},
+		map[string]interface{}{"ID": 2},
+	}}
+
+	t.Run("active", func(t *testing.T) {
+		var dst List
+		if err := NewDecoder().DecodeContext(context.Background(), src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(dst.Items) != 2 || dst.Items[1].ID != 2 {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var dst List
+		err := NewDecoder().DecodeContext(ctx, src, &dst)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("cancelled during decode", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		d := NewDecoder(WithCollectErrors())
+		calls := 0
+		d.RegisterTypeConverter(reflect.TypeFor[Item](), func(interface{}) (interface{}, error) {
+			calls++
+			cancel()
+			return Item{}, nil
+		})
+		var dst List
+		err := d.DecodeContext(ctx, src, &dst)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("expected decoding to stop after the first element, got %d calls", calls)
+		}
+	})
+
+	t.Run("not kept across calls", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		d := NewDecoder()
+		var dst List
+		_ = d.DecodeContext(ctx, src, &dst)
+		if err := d.Decode(src, &dst); err != nil {
+			t.Errorf("unexpected error after DecodeContext: %v", err)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// deepCopyValue returns a copy of v in which every nested map and slice is copied as well.
+// Other values are returned as-is. v itself is the source being decoded, which i2sReflect has
+// already recorded with descend, so only the values nested in it are checked for cycles.
+func (d *Decoder) deepCopyValue(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil //nolint:nilnil // nil copies to nil
+	}
+	c, err := d.deepCopyContents(reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+	return c.Interface(), nil
+}
+
+// deepCopyReflect recursively copies the maps and slices reachable from v. Like decoding, it is
+// bounded by WithMaxDepth and fails with ErrCyclicReference on maps that contain themselves.
+func (d *Decoder) deepCopyReflect(v reflect.Value) (reflect.Value, error) {
+	nested, depthErr := d.descend(v)
+	if depthErr != nil {
+		return reflect.Value{}, depthErr
+	}
+	if nested {
+		defer d.ascend()
+	}
+	return d.deepCopyContents(v)
+}
+
+// deepCopyContents copies v, copying the values nested in it with deepCopyReflect.
+func (d *Decoder) deepCopyContents(v reflect.Value) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v, nil
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, err := d.deepCopyReflect(iter.Value())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("key %v: %w", iter.Key(), err)
+			}
+			c.SetMapIndex(iter.Key(), elem)
+		}
+		return c, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, nil
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			elem, err := d.deepCopyReflect(v.Index(i))
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("index %d: %w", i, err)
+			}
+			c.Index(i).Set(elem)
+		}
+		return c, nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+		elem, err := d.deepCopyReflect(v.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(elem)
+		return c, nil
+	default:
+		return v, nil
+	}
+}
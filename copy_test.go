@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDeepCopyValue(t *testing.T) {
+	src := map[string]interface{}{
+		"list":   []interface{}{1, map[string]interface{}{"a": 1}},
+		"nested": map[string]interface{}{"b": []int{1, 2}},
+		"scalar": "x",
+		"nil":    nil,
+	}
+
+	d := NewDecoder()
+	v, err := d.deepCopyValue(src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cp, ok := v.(map[string]interface{})
+	if !ok || !reflect.DeepEqual(cp, src) {
+		t.Fatalf("copy %v differs from source %v", cp, src)
+	}
+
+	src["list"].([]interface{})[1].(map[string]interface{})["a"] = 2
+	src["nested"].(map[string]interface{})["b"].([]int)[0] = 9
+	src["scalar"] = "y"
+
+	if cp["list"].([]interface{})[1].(map[string]interface{})["a"] != 1 {
+		t.Error("nested map in slice was shared")
+	}
+	if cp["nested"].(map[string]interface{})["b"].([]int)[0] != 1 {
+		t.Error("nested slice was shared")
+	}
+	if cp["scalar"] != "x" {
+		t.Error("top-level map was shared")
+	}
+	if v, err := d.deepCopyValue(nil); v != nil || err != nil {
+		t.Errorf("expected nil copy of nil, got %v, %v", v, err)
+	}
+
+	t.Run("cyclic", func(t *testing.T) {
+		cyclic := map[string]interface{}{"a": 1}
+		cyclic["self"] = []interface{}{cyclic}
+		if _, err := NewDecoder().deepCopyValue(cyclic); !errors.Is(err, ErrCyclicReference) {
+			t.Errorf("expected ErrCyclicReference, got %v", err)
+		}
+
+		shared := map[string]interface{}{"b": 2}
+		if _, err := NewDecoder().deepCopyValue([]interface{}{shared, shared}); err != nil {
+			t.Errorf("expected shared values to be copied, got %v", err)
+		}
+	})
+}
+
+func TestWithDeepCopyInterfaces(t *testing.T) {
+	type Event struct {
+		Payload interface{}
+	}
+
+	newSource := func() (map[string]interface{}, map[string]interface{}) {
+		payload := map[string]interface{}{"user": map[string]interface{}{"name": "john"}}
+		return map[string]interface{}{"Payload": payload}, payload
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		src, payload := newSource()
+		var dst Event
+		if err := NewDecoder(WithDeepCopyInterfaces()).Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		payload["user"].(map[string]interface{})["name"] = "jane"
+		got := dst.Payload.(map[string]interface{})["user"].(map[string]interface{})["name"]
+		if got != "john" {
+			t.Errorf("expected decoded payload to be isolated, got %v", got)
+		}
+	})
+
+	t.Run("cyclic payload", func(t *testing.T) {
+		payload := map[string]interface{}{}
+		payload["self"] = payload
+		var dst Event
+		err := NewDecoder(WithDeepCopyInterfaces()).Decode(map[string]interface{}{"Payload": payload}, &dst)
+		if !errors.Is(err, ErrCyclicReference) {
+			t.Errorf("expected ErrCyclicReference, got %v", err)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		src, payload := newSource()
+		var dst Event
+		if err := NewDecoder().Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		payload["user"].(map[string]interface{})["name"] = "jane"
+		got := dst.Payload.(map[string]interface{})["user"].(map[string]interface{})["name"]
+		if got != "jane" {
+			t.Errorf("expected decoded payload to share the source, got %v", got)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// applyDefaults assigns the value of their `default` tag option to the decodable fields of
+// out whose keys are absent from the string-keyed map data. A key present with a zero value
+// keeps the zero value.
+func (d *Decoder) applyDefaults(data reflect.Value, out reflect.Value) error {
+	fieldsMap, err := d.mapStructFieldsByName(out)
+	if err != nil {
+		return err
+	}
+
+	present := d.presentFields(data, fieldsMap)
+	for _, field := range fieldsMap {
+		raw, ok := field.tag.value("default")
+		if !ok || !d.decodable(field) || present[field.name] {
+			continue
+		}
+		if field.allocate(); !field.value.CanSet() {
+			continue
+		}
+		value, err := d.parseDefault(field.value.Type(), raw)
+		if err != nil {
+			return fmt.Errorf("field %q: %w", field.name, err)
+		}
+		field.value.Set(value)
+	}
+	return nil
+}
+
+// parseDefault parses the raw value of a `default` tag option into a new value of fieldType,
+// following pointers. Strings are converted as form values are, so "30s" is a valid default
+// for a time.Duration field and "true" for a bool one.
+func (d *Decoder) parseDefault(fieldType reflect.Type, raw string) (reflect.Value, error) {
+	value := reflect.New(fieldType).Elem()
+	target := value
+	for target.Kind() == reflect.Pointer {
+		if err := d.allocatePtr(target); err != nil {
+			return reflect.Value{}, err
+		}
+		target = target.Elem()
+	}
+	if err := d.assignFormString(target, raw); err != nil {
+		return reflect.Value{}, fmt.Errorf("invalid default %q: %w", raw, err)
+	}
+	return value, nil
+}
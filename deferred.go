@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// DeferredDecoder holds a source map whose entries are decoded one at a time, on demand, see
+// Decoder.DecodeDeferred. It is safe for concurrent use.
+type DeferredDecoder struct {
+	mu       sync.Mutex
+	d        *Decoder
+	data     map[string]interface{}
+	prepared bool
+	err      error
+}
+
+// DecodeDeferred returns a DeferredDecoder for data, so that callers needing only a few of its
+// entries can decode each one with Get instead of populating a whole struct. The transformers
+// set with WithPipeline and WithExpandDotKeys run once, on the first call to Get.
+func (d *Decoder) DecodeDeferred(data map[string]interface{}) *DeferredDecoder {
+	return &DeferredDecoder{d: d, data: data}
+}
+
+// Get decodes the entry of the source map under key into the value pointed to by out, with
+// the conversions Decode applies to struct fields. It returns an error wrapping
+// ErrKeyNotFound if the source has no such key.
+func (dd *DeferredDecoder) Get(key string, out interface{}) error {
+	return dd.d.wrapError(out, dd.get(key, out))
+}
+
+// get is Get without WithWrapErrors.
+func (dd *DeferredDecoder) get(key string, out interface{}) error {
+	outVal := reflect.ValueOf(out)
+	if outVal.Kind() != reflect.Pointer || outVal.IsNil() {
+		return fmt.Errorf("out must be a non-nil pointer, got %T", out)
+	}
+
+	dd.mu.Lock()
+	defer dd.mu.Unlock()
+
+	if err := dd.prepare(); err != nil {
+		return err
+	}
+	value, ok := dd.data[key]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+	}
+	s := dd.d.session()
+	defer dd.d.release(s)
+	if err := s.i2sReflect(reflect.ValueOf(value), outVal.Elem()); err != nil {
+		return fmt.Errorf("key %q: %w", key, err)
+	}
+	return nil
+}
+
+// prepare reshapes the source map on first use, as decode does for the top-level map.
+func (dd *DeferredDecoder) prepare() error {
+	if dd.prepared {
+		return dd.err
+	}
+	dd.prepared = true
+	if len(dd.d.opts.Pipeline) > 0 || dd.d.opts.ExpandDotKeys {
+		dd.data, dd.err = dd.d.reshape(copyStringMap(reflect.ValueOf(dd.data)))
+	}
+	return dd.err
+}
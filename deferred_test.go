@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDecodeDeferred(t *testing.T) {
+	type Address struct{ City string }
+	data := map[string]interface{}{
+		"name":    "john",
+		"age":     30,
+		"timeout": "5s",
+		"address": map[string]interface{}{"City": "Berlin"},
+		"note":    nil,
+	}
+
+	dd := NewDecoder().DecodeDeferred(data)
+
+	var age int64
+	if err := dd.Get("age", &age); err != nil || age != 30 {
+		t.Errorf("unexpected age %d, error: %v", age, err)
+	}
+	var timeout time.Duration
+	if err := dd.Get("timeout", &timeout); err != nil || timeout != 5*time.Second {
+		t.Errorf("unexpected timeout %v, error: %v", timeout, err)
+	}
+	var address *Address
+	if err := dd.Get("address", &address); err != nil || address == nil || address.City != "Berlin" {
+		t.Errorf("unexpected address %+v, error: %v", address, err)
+	}
+	note := "keep"
+	if err := dd.Get("note", &note); err != nil || note != "keep" {
+		t.Errorf("unexpected note %q, error: %v", note, err)
+	}
+
+	t.Run("missing key", func(t *testing.T) {
+		var s string
+		if err := dd.Get("email", &s); !errors.Is(err, ErrKeyNotFound) {
+			t.Errorf("expected ErrKeyNotFound, got %v", err)
+		}
+	})
+
+	t.Run("conversion error", func(t *testing.T) {
+		var n int
+		if err := dd.Get("name", &n); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("non-pointer out", func(t *testing.T) {
+		var s string
+		if err := dd.Get("name", s); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("dot keys are expanded", func(t *testing.T) {
+		dd := NewDecoder(WithExpandDotKeys()).DecodeDeferred(map[string]interface{}{"db.Host": "localhost"})
+		var db struct{ Host string }
+		if err := dd.Get("db", &db); err != nil || db.Host != "localhost" {
+			t.Errorf("unexpected result %+v, error: %v", db, err)
+		}
+	})
+
+	t.Run("concurrent access", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var name string
+				if err := dd.Get("name", &name); err != nil || name != "john" {
+					t.Errorf("unexpected name %q, error: %v", name, err)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// defaultMaxDepth is the nesting limit used unless another one is set with WithMaxDepth.
+const defaultMaxDepth = 100
+
+// maxDepth returns the maximum nesting of source values the decoder descends into.
+func (o *DecoderOptions) maxDepth() int {
+	if o.MaxDepth <= 0 {
+		return defaultMaxDepth
+	}
+	return o.MaxDepth
+}
+
+// descend records that i2sReflect is decoding the maps, pointers, slices, arrays or structs
+// nested in data. It fails with ErrMaxDepthExceeded when the nesting goes beyond MaxDepth,
+// and with ErrCyclicReference when data is a map or pointer already being decoded further up,
+// i.e. the source contains itself. It reports whether the caller must call ascend when done.
+func (d *Decoder) descend(data reflect.Value) (bool, error) {
+	var ptr uintptr
+	switch data.Kind() {
+	case reflect.Map, reflect.Pointer:
+		if data.IsNil() {
+			return false, nil
+		}
+		ptr = data.Pointer()
+		if slices.Contains(d.ancestors, ptr) {
+			return false, fmt.Errorf("%w: %s value contains itself", ErrCyclicReference, data.Type())
+		}
+	case reflect.Slice, reflect.Array, reflect.Struct:
+	default:
+		return false, nil
+	}
+
+	if limit := d.opts.maxDepth(); len(d.ancestors) >= limit {
+		return false, fmt.Errorf("%w: more than %d levels", ErrMaxDepthExceeded, limit)
+	}
+	// values that cannot form cycles are recorded as 0, which no map or pointer matches.
+	d.ancestors = append(d.ancestors, ptr)
+	return true, nil
+}
+
+// ascend undoes the matching descend.
+func (d *Decoder) ascend() {
+	d.ancestors = d.ancestors[:len(d.ancestors)-1]
+}
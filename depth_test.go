@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+type depthNode struct {
+	Name     string
+	Next     *depthNode
+	Children []depthNode
+}
+
+// nestedNodes returns a source map whose Next keys nest n levels deep.
+func nestedNodes(n int) map[string]interface{} {
+	root := map[string]interface{}{"Name": "0"}
+	cur := root
+	for i := 1; i < n; i++ {
+		next := map[string]interface{}{"Name": "n"}
+		cur["Next"] = next
+		cur = next
+	}
+	return root
+}
+
+func TestWithMaxDepth(t *testing.T) {
+	t.Run("default limit", func(t *testing.T) {
+		var dst depthNode
+		if err := NewDecoder().Decode(nestedNodes(50), &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		err := NewDecoder().Decode(nestedNodes(defaultMaxDepth+1), &dst)
+		if !errors.Is(err, ErrMaxDepthExceeded) {
+			t.Fatalf("expected ErrMaxDepthExceeded, got %v", err)
+		}
+	})
+
+	t.Run("custom limit", func(t *testing.T) {
+		var dst depthNode
+		if err := NewDecoder(WithMaxDepth(500)).Decode(nestedNodes(300), &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		src := map[string]interface{}{"Children": []interface{}{map[string]interface{}{"Name": "c"}}}
+		if err := NewDecoder(WithMaxDepth(2)).Decode(src, &dst); !errors.Is(err, ErrMaxDepthExceeded) {
+			t.Fatalf("expected ErrMaxDepthExceeded, got %v", err)
+		}
+	})
+
+	t.Run("decoder is reusable after an error", func(t *testing.T) {
+		d := NewDecoder(WithMaxDepth(10))
+		var dst depthNode
+		if err := d.Decode(nestedNodes(20), &dst); !errors.Is(err, ErrMaxDepthExceeded) {
+			t.Fatalf("expected ErrMaxDepthExceeded, got %v", err)
+		}
+		if err := d.Decode(nestedNodes(5), &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestCyclicSource(t *testing.T) {
+	t.Run("map containing itself", func(t *testing.T) {
+		src := map[string]interface{}{"Name": "loop"}
+		src["Next"] = src
+		var dst depthNode
+		if err := NewDecoder(WithMaxDepth(1000)).Decode(src, &dst); !errors.Is(err, ErrCyclicReference) {
+			t.Fatalf("expected ErrCyclicReference, got %v", err)
+		}
+	})
+
+	t.Run("pointer cycle", func(t *testing.T) {
+		type Link struct {
+			Next *Link
+		}
+		src := &depthNode{Name: "a"}
+		src.Next = src
+		var dst Link
+		if err := NewDecoder().Decode(map[string]interface{}{"Next": src}, &dst); !errors.Is(err, ErrCyclicReference) {
+			t.Fatalf("expected ErrCyclicReference, got %v", err)
+		}
+	})
+
+	t.Run("shared values are not cycles", func(t *testing.T) {
+		shared := map[string]interface{}{"Name": "shared"}
+		src := map[string]interface{}{
+			"Next":     shared,
+			"Children": []interface{}{shared, shared},
+		}
+		var dst depthNode
+		if err := NewDecoder().Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Next.Name != "shared" || len(dst.Children) != 2 || dst.Children[1].Name != "shared" {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+}
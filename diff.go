@@ -0,0 +1,56 @@
+package main
+
+import "reflect"
+
+// FieldDiff holds the encoded old and new values of a field reported by Diff.
+type FieldDiff struct {
+	Old interface{}
+	New interface{}
+}
+
+// Diff encodes the structs, or pointers to structs, a and b with s2i and returns the fields
+// whose values differ, keyed by their encoded names. Changed leaf values are reported as a
+// FieldDiff; fields holding a nested struct on both sides are reported as a nested
+// map[string]interface{} of their own changed fields. A field present on one side only is
+// reported with a nil Old or New. Diff returns an empty map if a and b encode equally.
+func Diff(a, b interface{}) (map[string]interface{}, error) {
+	old, err := s2i(a)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := s2i(b)
+	if err != nil {
+		return nil, err
+	}
+	return diffMaps(old, updated), nil
+}
+
+// diffMaps compares two encoded structs key by key, recursing into values that are encoded
+// structs on both sides.
+func diffMaps(old, updated map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	for key, oldValue := range old {
+		newValue, ok := updated[key]
+		if !ok {
+			out[key] = FieldDiff{Old: oldValue}
+			continue
+		}
+		oldMap, oldIsMap := oldValue.(map[string]interface{})
+		newMap, newIsMap := newValue.(map[string]interface{})
+		if oldIsMap && newIsMap {
+			if nested := diffMaps(oldMap, newMap); len(nested) > 0 {
+				out[key] = nested
+			}
+			continue
+		}
+		if !reflect.DeepEqual(oldValue, newValue) {
+			out[key] = FieldDiff{Old: oldValue, New: newValue}
+		}
+	}
+	for key, newValue := range updated {
+		if _, ok := old[key]; !ok {
+			out[key] = FieldDiff{New: newValue}
+		}
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	type Address struct {
+		City string
+		Zip  string
+	}
+	type User struct {
+		Name    string
+		Age     int
+		Tags    []string
+		Address Address
+		Manager *Address
+	}
+
+	a := User{Name: "john", Age: 30, Tags: []string{"a"}, Address: Address{City: "Berlin", Zip: "10115"}}
+	b := User{Name: "john", Age: 31, Tags: []string{"a", "b"}, Address: Address{City: "Paris", Zip: "10115"},
+		Manager: &Address{City: "Oslo"}}
+
+	diff, err := Diff(a, &b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"Age":     FieldDiff{Old: 30, New: 31},
+		"Tags":    FieldDiff{Old: []interface{}{"a"}, New: []interface{}{"a", "b"}},
+		"Address": map[string]interface{}{"City": FieldDiff{Old: "Berlin", New: "Paris"}},
+		"Manager": FieldDiff{New: map[string]interface{}{"City": "Oslo", "Zip": ""}},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Errorf("expected %#v, got %#v", want, diff)
+	}
+
+	t.Run("equal", func(t *testing.T) {
+		diff, err := Diff(a, a)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(diff) != 0 {
+			t.Errorf("expected no changes, got %v", diff)
+		}
+	})
+
+	t.Run("different types", func(t *testing.T) {
+		type Other struct{ Name string }
+		diff, err := Diff(Other{Name: "john"}, a)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := diff["Age"]; !reflect.DeepEqual(got, FieldDiff{New: 30}) {
+			t.Errorf("unexpected diff for added field: %#v", got)
+		}
+		if _, ok := diff["Name"]; ok {
+			t.Error("unchanged field reported")
+		}
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		if _, err := Diff(1, a); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestDiffNilEmbeddedPointer(t *testing.T) {
+	type Base struct {
+		ID int
+	}
+	type Outer struct {
+		*Base
+		Name string
+	}
+
+	a, b := Outer{Name: "a"}, Outer{Name: "b"}
+	diff, err := Diff(&a, &b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := map[string]interface{}{"Name": FieldDiff{Old: "a", New: "b"}}; !reflect.DeepEqual(diff, want) {
+		t.Errorf("expected %#v, got %#v", want, diff)
+	}
+	if a.Base != nil || b.Base != nil {
+		t.Error("expected Diff to leave the embedded pointers nil")
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// expandDotKeys returns a copy of m in which keys containing dots are split into nested maps,
+// so that {"db.host": "x"} becomes {"db": {"host": "x"}}. Dotted keys are merged with nested
+// maps already present under their prefix. A prefix whose value is not a map is a conflict.
+// The maps of m are never modified.
+func expandDotKeys(m map[string]interface{}) (map[string]interface{}, error) {
+	plain := make(map[string]interface{}, len(m))
+	tree := make(map[string]interface{})
+	for key, value := range m {
+		if !strings.Contains(key, ".") {
+			plain[key] = value
+			continue
+		}
+		if err := insertPath(tree, strings.Split(key, "."), value); err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+	}
+	return mergeTrees(plain, tree)
+}
+
+// insertPath stores value in tree under the nested path, creating intermediate maps.
+func insertPath(tree map[string]interface{}, path []string, value interface{}) error {
+	node := tree
+	for i, part := range path[:len(path)-1] {
+		child, ok := node[part]
+		if !ok {
+			next := make(map[string]interface{})
+			node[part] = next
+			node = next
+			continue
+		}
+		next, ok := child.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%q is not a map", strings.Join(path[:i+1], "."))
+		}
+		// the map may be a source value, which must not be modified.
+		next = maps.Clone(next)
+		node[part] = next
+		node = next
+	}
+
+	last := path[len(path)-1]
+	if existing, ok := node[last]; ok {
+		merged, err := mergeValues(existing, value)
+		if err != nil {
+			return fmt.Errorf("%q: %w", strings.Join(path, "."), err)
+		}
+		value = merged
+	}
+	node[last] = value
+	return nil
+}
+
+// mergeTrees returns a new map holding the keys of a and b, merging values present in both.
+func mergeTrees(a, b map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(a)+len(b))
+	maps.Copy(out, a)
+	for key, value := range b {
+		if existing, ok := out[key]; ok {
+			merged, err := mergeValues(existing, value)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key, err)
+			}
+			value = merged
+		}
+		out[key] = value
+	}
+	return out, nil
+}
+
+// mergeValues merges two values set for the same key, which must both be maps.
+func mergeValues(a, b interface{}) (interface{}, error) {
+	am, aok := a.(map[string]interface{})
+	bm, bok := b.(map[string]interface{})
+	if !aok || !bok {
+		return nil, fmt.Errorf("conflicting values %v and %v", a, b)
+	}
+	return mergeTrees(am, bm)
+}
+
+// DecodePath decodes the value found at the dot-separated path of data into out, so that
+// DecodePath(data, "server.tls", &tlsCfg) decodes data["server"]["tls"]. At each level a key
+// holding the whole remaining path, as in flat maps like {"server.tls": ...}, takes precedence
+// over descending one segment. An empty path decodes data itself.
+func DecodePath(data map[string]interface{}, path string, out interface{}) error {
+	value, err := lookupPath(data, path)
+	if err != nil {
+		return err
+	}
+	return i2s(value, out)
+}
+
+// lookupPath returns the value at the dot-separated path of data.
+func lookupPath(data map[string]interface{}, path string) (interface{}, error) {
+	if path == "" {
+		return data, nil
+	}
+
+	node := data
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		if value, ok := node[strings.Join(parts[i:], ".")]; ok {
+			return value, nil
+		}
+		value, ok := node[part]
+		if !ok {
+			return nil, fmt.Errorf("path %q: key %q not found", path, strings.Join(parts[:i+1], "."))
+		}
+		if i == len(parts)-1 {
+			return value, nil
+		}
+		if node, ok = value.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("path %q: %q is %T, not a map", path, strings.Join(parts[:i+1], "."), value)
+		}
+	}
+	return node, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandDotKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     map[string]interface{}
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			"nested",
+			map[string]interface{}{"db.host": "localhost", "db.port": 5432, "debug": true},
+			map[string]interface{}{"db": map[string]interface{}{"host": "localhost", "port": 5432}, "debug": true},
+			false,
+		},
+		{
+			"deep",
+			map[string]interface{}{"a.b.c": 1},
+			map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": 1}}},
+			false,
+		},
+		{
+			"merged with existing map",
+			map[string]interface{}{"db": map[string]interface{}{"user": "root"}, "db.host": "localhost"},
+			map[string]interface{}{"db": map[string]interface{}{"user": "root", "host": "localhost"}},
+			false,
+		},
+		{"conflict with scalar", map[string]interface{}{"db": "x", "db.host": "localhost"}, nil, true},
+		{"conflict between paths", map[string]interface{}{"a.b": 1, "a.b.c": 2}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandDotKeys(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("source is not modified", func(t *testing.T) {
+		nested := map[string]interface{}{"user": "root"}
+		if _, err := expandDotKeys(map[string]interface{}{"db": nested, "db.host": "x"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := expandDotKeys(map[string]interface{}{"a.b": nested, "a.b.c": "x"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(nested) != 1 {
+			t.Errorf("nested source map was modified: %v", nested)
+		}
+	})
+}
+
+func TestWithExpandDotKeys(t *testing.T) {
+	type DBConfig struct {
+		Host string `gomap:"host"`
+		Port int    `gomap:"port"`
+	}
+	type Config struct {
+		DB    DBConfig `gomap:"db"`
+		Debug bool     `gomap:"debug"`
+	}
+
+	src := map[string]interface{}{"db.host": "localhost", "db.port": 5432, "debug": true}
+
+	var dst Config
+	if err := NewDecoder(WithExpandDotKeys()).Decode(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Config{DB: DBConfig{Host: "localhost", Port: 5432}, Debug: true}
+	if dst != want {
+		t.Errorf("expected %+v, got %+v", want, dst)
+	}
+}
+
+func TestDecodePath(t *testing.T) {
+	type TLSConfig struct {
+		Cert string `gomap:"cert"`
+		Key  string `gomap:"key"`
+	}
+
+	data := map[string]interface{}{
+		"server": map[string]interface{}{
+			"port": 443,
+			"tls":  map[string]interface{}{"cert": "a.pem", "key": "a.key"},
+		},
+		"proxy.tls": map[string]interface{}{"cert": "b.pem"},
+		"name":      "api",
+	}
+
+	t.Run("nested", func(t *testing.T) {
+		var dst TLSConfig
+		if err := DecodePath(data, "server.tls", &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst != (TLSConfig{Cert: "a.pem", Key: "a.key"}) {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("flat key", func(t *testing.T) {
+		var dst TLSConfig
+		if err := DecodePath(data, "proxy.tls", &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Cert != "b.pem" {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("scalar leaf", func(t *testing.T) {
+		var port int
+		if err := DecodePath(data, "server.port", &port); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if port != 443 {
+			t.Errorf("expected 443, got %d", port)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var dst TLSConfig
+		for _, path := range []string{"server.missing", "name.tls", "missing"} {
+			if err := DecodePath(data, path, &dst); err == nil {
+				t.Errorf("%q: expected error", path)
+			}
+		}
+	})
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+	"net/url"
+	"reflect"
+	"time"
+)
+
+// s2i is the counterpart of i2s: it encodes the struct, or pointer to struct, in into a
+// map[string]interface{} using the default options.
+func s2i(in interface{}) (map[string]interface{}, error) {
+	return NewDecoder().Encode(in)
+}
+
+// Encode encodes the struct, or pointer to struct, in into a map[string]interface{} keyed by
+// the same effective names Decode reads: the tag name when set, otherwise the Go field name.
+// Fields tagged `gomap:"-"` or encode_ignore, and fields outside the groups selected with
+// WithGroups, are left out. Nested structs and maps with string keys become
+// map[string]interface{}, slices and arrays become []interface{} and nil pointers become nil.
+// Values implementing encoding.TextMarshaler are stored as the string returned by MarshalText;
+// other []byte values and time.Time values are kept as they are. The nullable types of
+// database/sql encode as their value, or nil when not Valid. The entries of a field tagged
+// `remain` are added alongside the other fields.
+func (d *Decoder) Encode(in interface{}) (map[string]interface{}, error) {
+	v := dereferencePtr(reflect.ValueOf(in))
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("in must be a struct or a pointer to a struct, got %T", in)
+	}
+	s := d.session()
+	defer d.release(s)
+	return s.encodeStruct(v, true)
+}
+
+// encodeStruct encodes the struct value v into a map. Values implementing
+// encoding.TextMarshaler are marshaled only if text is true.
+func (d *Decoder) encodeStruct(v reflect.Value, text bool) (map[string]interface{}, error) {
+	fieldsMap, err := d.mapStructFieldsByName(v)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]interface{}, len(fieldsMap))
+	for name, field := range fieldsMap {
+		if !field.value.IsValid() || !field.value.CanInterface() || field.tag.skipEncode() || !d.opts.inGroups(field.tag) {
+			continue
+		}
+		value, err := d.encodeValue(field.value, text)
+		if err != nil {
+			return nil, fmt.Errorf("encoding field %q: %w", field.name, err)
+		}
+		out[name] = value
+	}
+	return out, d.encodeRemain(v, out, text)
+}
+
+// encodeRemain adds the entries of the map field of the struct v tagged `remain` to its
+// encoded form out, leaving the keys of other fields as they are.
+func (d *Decoder) encodeRemain(v reflect.Value, out map[string]interface{}, text bool) error {
+	meta := d.structMeta(v.Type())
+	if len(meta.remain) != 1 {
+		return nil
+	}
+	field := meta.remain[0].field(v)
+	if !field.value.IsValid() || !field.value.CanInterface() || field.tag.skipEncode() {
+		return nil
+	}
+	rest, ok := field.value.Interface().(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for key, value := range rest {
+		if _, taken := out[key]; taken {
+			continue
+		}
+		if value == nil {
+			out[key] = nil
+			continue
+		}
+		encoded, err := d.encodeValue(reflect.ValueOf(value), text)
+		if err != nil {
+			return fmt.Errorf("encoding field %q: %w", field.name, err)
+		}
+		out[key] = encoded
+	}
+	return nil
+}
+
+// encodeValue converts v into its encoded form, see encodeStruct. Like decoding, encoding is
+// bounded by WithMaxDepth and fails on values that contain themselves.
+func (d *Decoder) encodeValue(v reflect.Value, text bool) (interface{}, error) {
+	if isNilValue(v) {
+		return nil, nil //nolint:nilnil // nil pointers, slices and maps encode as nil
+	}
+	nested, depthErr := d.descend(v)
+	if depthErr != nil {
+		return nil, depthErr
+	}
+	if nested {
+		defer d.ascend()
+	}
+	if text {
+		if s, ok, err := marshalText(v); ok {
+			return s, err
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return d.encodeValue(v.Elem(), text)
+	case reflect.Struct:
+		if v.Type() == reflect.TypeFor[time.Time]() || (!text && isLeafStruct(v.Type())) {
+			return v.Interface(), nil
+		}
+		if isSQLNull(v.Type()) {
+			if !v.Field(1).Bool() {
+				return nil, nil //nolint:nilnil // invalid nullable values encode as nil
+			}
+			return d.encodeValue(v.Field(0), text)
+		}
+		return d.encodeStruct(v, text)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface(), nil
+		}
+		out := make([]interface{}, v.Len())
+		for i := range v.Len() {
+			elem, err := d.encodeValue(v.Index(i), text)
+			if err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+			out[i] = elem
+		}
+		return out, nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return v.Interface(), nil
+		}
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, err := d.encodeValue(iter.Value(), text)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", iter.Key().String(), err)
+			}
+			out[iter.Key().String()] = elem
+		}
+		return out, nil
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil, fmt.Errorf("unsupported kind: %s", v.Kind())
+	default:
+		return v.Interface(), nil
+	}
+}
+
+// marshalText encodes v with MarshalText when it implements encoding.TextMarshaler, either
+// directly or through its address. It reports false for other values and for time.Time,
+// which is kept as is.
+func marshalText(v reflect.Value) (string, bool, error) {
+	if v.Type() == reflect.TypeFor[time.Time]() || !v.CanInterface() {
+		return "", false, nil
+	}
+
+	marshaler, ok := v.Interface().(encoding.TextMarshaler)
+	if !ok && v.CanAddr() {
+		marshaler, ok = v.Addr().Interface().(encoding.TextMarshaler)
+	}
+	if !ok {
+		return "", false, nil
+	}
+
+	text, err := marshaler.MarshalText()
+	if err != nil {
+		return "", true, err
+	}
+	return string(text), true, nil
+}
+
+// isLeafStruct reports whether values of the struct type t are kept as they are when encoding
+// without text marshaling, because their state is not held in exported fields: big.Int,
+// big.Float, url.URL, whose Userinfo would be lost, and types whose pointer implements both
+// encoding.TextMarshaler and encoding.TextUnmarshaler and that have no exported fields. The
+// decoder copies such values instead of decoding them field by field, see assignTextValue.
+func isLeafStruct(t reflect.Type) bool {
+	switch t {
+	case reflect.TypeFor[big.Int](), reflect.TypeFor[big.Float](), reflect.TypeFor[url.URL]():
+		return true
+	}
+	return isTextStruct(t)
+}
+
+// isTextStruct reports whether the struct type t has no exported fields and round-trips
+// through text, see isLeafStruct.
+func isTextStruct(t reflect.Type) bool {
+	p := reflect.PointerTo(t)
+	if !p.Implements(reflect.TypeFor[encoding.TextMarshaler]()) ||
+		!p.Implements(reflect.TypeFor[encoding.TextUnmarshaler]()) {
+		return false
+	}
+	for i := range t.NumField() {
+		if t.Field(i).IsExported() {
+			return false
+		}
+	}
+	return true
+}
+
+// assignTextValue copies data into out when both are of the same struct type satisfying
+// isTextStruct, by marshaling data to text and unmarshaling it into a new value. It reports
+// false for any other combination.
+func (d *Decoder) assignTextValue(data reflect.Value, out reflect.Value) (bool, error) {
+	t := data.Type()
+	if indirectType(out.Type()) != t || !isTextStruct(t) {
+		return false, nil
+	}
+
+	src := reflect.New(t)
+	src.Elem().Set(data)
+	text, err := src.Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return true, err
+	}
+	value := reflect.New(t)
+	if err := value.Interface().(encoding.TextUnmarshaler).UnmarshalText(text); err != nil {
+		return true, err
+	}
+	return true, d.setIndirect(out, value.Elem())
+}
+
+// isNilValue reports whether v is a nil pointer, interface, slice or map.
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// CopyStruct copies the fields of the struct, or pointer to struct, src into the struct pointed
+// to by dst, matching fields by their effective names and converting values as Decode does.
+// Fields of dst without a counterpart in src are left unchanged.
+func CopyStruct(src, dst interface{}) error {
+	return NewDecoder().Copy(src, dst)
+}
+
+// Copy deep-copies the struct, or pointer to struct, src into the struct pointed to by dst
+// with the decoder's options. src is encoded into a map, without text marshaling, and the map
+// is decoded into dst, so dst shares no pointers, slices or maps with src. Values whose state
+// is not held in exported fields, such as big.Int, url.URL and text-marshalable structs, are
+// copied whole, see isLeafStruct. Values held in interface{} fields are copied in their
+// encoded form, e.g. a []int becomes a []interface{}.
+func (d *Decoder) Copy(src, dst interface{}) error {
+	return d.wrapError(dst, d.copy(src, dst))
+}
+
+// copy is Copy without WithWrapErrors.
+func (d *Decoder) copy(src, dst interface{}) error {
+	v := dereferencePtr(reflect.ValueOf(src))
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("src must be a struct or a pointer to a struct, got %T", src)
+	}
+	s := d.session()
+	defer d.release(s)
+	m, err := s.encodeStruct(v, false)
+	if err != nil {
+		return err
+	}
+	return s.decode(m, dst)
+}
+
+// assignStruct decodes the struct data into out by encoding it into a map first, so that
+// fields are matched by effective name and converted exactly as for map sources. A map
+// destination receives the encoded fields. Text marshaling is skipped so that such fields keep
+// their type.
+func (d *Decoder) assignStruct(data reflect.Value, out reflect.Value) error {
+	m, err := d.encodeStruct(data, false)
+	if err != nil {
+		return err
+	}
+	if isMapDestination(out) {
+		return d.assignMapToMap(reflect.ValueOf(m), out)
+	}
+	return d.assignMap(reflect.ValueOf(m), out)
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	type Address struct {
+		City string `gomap:"city"`
+	}
+	type User struct {
+		ID       int `gomap:"user_id"`
+		Name     string
+		Password string `gomap:",encode_ignore"`
+		Secret   string `gomap:"-"`
+		Address  Address
+		Previous *Address
+		Manager  *Address
+		Tags     []string
+		Scores   map[string]int
+		Friends  []Address
+		Data     []byte
+		private  int
+	}
+
+	in := User{
+		ID:       1,
+		Name:     "john",
+		Password: "hunter2",
+		Secret:   "x",
+		Address:  Address{City: "Berlin"},
+		Manager:  &Address{City: "Paris"},
+		Tags:     []string{"a", "b"},
+		Scores:   map[string]int{"go": 10},
+		Friends:  []Address{{City: "Rome"}},
+		Data:     []byte("raw"),
+		private:  3,
+	}
+
+	got, err := s2i(&in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"user_id":  1,
+		"Name":     "john",
+		"Address":  map[string]interface{}{"city": "Berlin"},
+		"Previous": nil,
+		"Manager":  map[string]interface{}{"city": "Paris"},
+		"Tags":     []interface{}{"a", "b"},
+		"Scores":   map[string]interface{}{"go": 10},
+		"Friends":  []interface{}{map[string]interface{}{"city": "Rome"}},
+		"Data":     []byte("raw"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Encode() = %#v\nwant %#v", got, want)
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		var out User
+		if err := i2s(got, &out); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.ID != in.ID || out.Address != in.Address || *out.Manager != *in.Manager ||
+			!reflect.DeepEqual(out.Tags, in.Tags) || !reflect.DeepEqual(out.Friends, in.Friends) ||
+			!reflect.DeepEqual(out.Scores, in.Scores) {
+			t.Errorf("round trip mismatch: %+v", out)
+		}
+	})
+
+	t.Run("groups", func(t *testing.T) {
+		type Profile struct {
+			Name  string
+			Email string `gomap:",group=private"`
+		}
+		got, err := NewDecoder(WithGroups("public")).Encode(Profile{Name: "john", Email: "j@x"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 1 || got["Name"] != "john" {
+			t.Errorf("unexpected result: %v", got)
+		}
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		if _, err := s2i(42); err == nil {
+			t.Error("expected error for non-struct input")
+		}
+	})
+}
+
+func TestCopyStruct(t *testing.T) {
+	type UserDTO struct {
+		ID       int64
+		Name     string `gomap:"full_name"`
+		Age      float64
+		Password string `gomap:",encode_ignore"`
+		Extra    string
+		Address  struct{ City string }
+	}
+	type User struct {
+		ID       int
+		FullName string `gomap:"full_name"`
+		Age      int
+		Password string
+		Role     string
+		Address  struct{ City string }
+	}
+
+	src := UserDTO{ID: 1, Name: "john", Age: 30, Password: "x", Extra: "y"}
+	src.Address.City = "Berlin"
+
+	dst := User{Role: "admin"}
+	if err := CopyStruct(&src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := User{ID: 1, FullName: "john", Age: 30, Role: "admin"}
+	want.Address.City = "Berlin"
+	if dst != want {
+		t.Errorf("expected %+v, got %+v", want, dst)
+	}
+
+	t.Run("nested struct field", func(t *testing.T) {
+		type Outer struct{ Inner UserDTO }
+		type Target struct{ Inner User }
+		var dst Target
+		if err := NewDecoder().Decode(Outer{Inner: src}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Inner.FullName != "john" {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("incompatible field", func(t *testing.T) {
+		type A struct{ ID string }
+		type B struct{ ID int }
+		if err := CopyStruct(A{ID: "x"}, &B{}); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+type level int
+
+func (l level) MarshalText() ([]byte, error) {
+	switch l {
+	case 0:
+		return []byte("debug"), nil
+	case 1:
+		return []byte("info"), nil
+	default:
+		return nil, fmt.Errorf("invalid level %d", int(l))
+	}
+}
+
+func TestEncodeTextMarshaler(t *testing.T) {
+	type Host struct {
+		Addr    net.IP
+		Level   level
+		Levels  []level
+		Pointer *level
+	}
+
+	info := level(1)
+	got, err := s2i(Host{Addr: net.ParseIP("10.0.0.1"), Level: 1, Levels: []level{0, 1}, Pointer: &info})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"Addr":    "10.0.0.1",
+		"Level":   "info",
+		"Levels":  []interface{}{"debug", "info"},
+		"Pointer": "info",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %#v, got %#v", want, got)
+	}
+
+	if _, err := s2i(Host{Level: 7}); err == nil {
+		t.Error("expected MarshalText error")
+	}
+
+	t.Run("CopyStruct keeps types", func(t *testing.T) {
+		var dst Host
+		if err := CopyStruct(Host{Addr: net.ParseIP("10.0.0.1"), Level: 1}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !dst.Addr.Equal(net.ParseIP("10.0.0.1")) || dst.Level != 1 {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+}
+
+func TestEncodeCyclicValue(t *testing.T) {
+	type Node struct {
+		Name string
+		Next *Node
+	}
+	n := &Node{Name: "a"}
+	n.Next = n
+	if _, err := s2i(n); !errors.Is(err, ErrCyclicReference) {
+		t.Fatalf("expected ErrCyclicReference, got %v", err)
+	}
+}
+
+func TestEncodeNilEmbeddedPointer(t *testing.T) {
+	type Base struct {
+		ID int
+	}
+	type Outer struct {
+		*Base
+		Name string
+	}
+
+	src := &Outer{Name: "a"}
+	got, err := s2i(src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := map[string]interface{}{"Name": "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if src.Base != nil {
+		t.Error("expected Encode to leave the embedded pointer nil")
+	}
+
+	var dst Outer
+	if err := CopyStruct(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Base != nil || dst.Name != "a" {
+		t.Errorf("unexpected copy: %+v", dst)
+	}
+}
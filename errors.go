@@ -0,0 +1,204 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ErrNilSource is returned for nil source values when NilSourceError is configured.
+var ErrNilSource = errors.New("nil source value")
+
+// ErrMaxDepthExceeded is returned when the source nests deeper than the limit set with
+// WithMaxDepth.
+var ErrMaxDepthExceeded = errors.New("maximum decoding depth exceeded")
+
+// ErrCyclicReference is returned when a source map or pointer contains itself.
+var ErrCyclicReference = errors.New("cyclic reference in source")
+
+// ErrKeyNotFound is returned by DeferredDecoder.Get for keys absent from the source.
+var ErrKeyNotFound = errors.New("key not found in source")
+
+// wrapError prefixes err, returned by a call decoding into out, with the type of out when
+// WithWrapErrors is set.
+func (d *Decoder) wrapError(out interface{}, err error) error {
+	if err == nil || !d.opts.WrapErrors {
+		return err
+	}
+	return fmt.Errorf("Decode into %T: %w", out, err) //nolint:staticcheck // the prefix names the call
+}
+
+// FieldCollisionError is returned when several struct fields resolve to the same
+// effective name and the collision cannot be resolved.
+type FieldCollisionError struct {
+	EffectiveName string
+	Fields        []string
+}
+
+func (e *FieldCollisionError) Error() string {
+	return fmt.Sprintf("fields %s collide on name %q", strings.Join(e.Fields, ", "), e.EffectiveName)
+}
+
+// DecodeError reports a failure to decode the value of a specific struct field.
+type DecodeError struct {
+	// Field is the name of the struct field that failed to decode.
+	Field string
+	// Path locates Field from the top-level struct: the names of the enclosing fields and the
+	// slice indices and map keys leading to nested structs, ending with Field itself.
+	Path []string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	name := e.Field
+	if len(e.Path) > 0 {
+		name = strings.Join(e.Path, ".")
+	}
+	return fmt.Sprintf("decoding field %q: %v", name, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeErrors holds every per-field error of a decode run with WithCollectErrors.
+type DecodeErrors []error
+
+func (e DecodeErrors) Error() string {
+	return errors.Join(e...).Error()
+}
+
+func (e DecodeErrors) Unwrap() []error {
+	return e
+}
+
+// fieldError attributes err, returned while decoding field, to that field: the paths of the
+// field errors err holds are prefixed with the field name, and any other error is wrapped in a
+// DecodeError for the field.
+func fieldError(field structField, err error) error {
+	if errs, ok := err.(DecodeErrors); ok { //nolint:errorlint // collected errors are returned unwrapped
+		for i, e := range errs {
+			errs[i] = fieldError(field, e)
+		}
+		return errs
+	}
+	if prefixPath(err, field.name) {
+		return err
+	}
+	return &DecodeError{Field: field.name, Path: []string{field.name}, Err: err}
+}
+
+// prefixPath prepends elem to the paths of the field errors held by err, which are either a
+// DecodeErrors or wrap a *DecodeError, and reports whether err holds any.
+func prefixPath(err error, elem string) bool {
+	if errs, ok := err.(DecodeErrors); ok { //nolint:errorlint // collected errors are returned unwrapped
+		for _, e := range errs {
+			prefixPath(e, elem)
+		}
+		return true
+	}
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		return false
+	}
+	if len(decodeErr.Path) == 0 {
+		decodeErr.Path = []string{decodeErr.Field}
+	}
+	decodeErr.Path = append([]string{elem}, decodeErr.Path...)
+	return true
+}
+
+// orNil returns e as an error, or nil if it holds no errors.
+func (e DecodeErrors) orNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// JSONSyntaxError is returned when the input of DecodeJSON is not valid JSON.
+type JSONSyntaxError struct {
+	// Offset is the number of bytes read before the error.
+	Offset int64
+	Err    error
+}
+
+func (e *JSONSyntaxError) Error() string {
+	return fmt.Sprintf("invalid JSON at offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *JSONSyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownKeysError is returned in strict mode when source keys have no matching struct field.
+type UnknownKeysError struct {
+	// Keys lists the unknown keys in sorted order.
+	Keys []string
+	// Suggestions maps unknown keys to the closest field name, when there is one.
+	Suggestions map[string]string
+}
+
+// newUnknownKeysError builds an *UnknownKeysError for keys, suggesting names from fields.
+func newUnknownKeysError(keys []string, fields map[string]structField) *UnknownKeysError {
+	e := &UnknownKeysError{Keys: slices.Sorted(slices.Values(keys)), Suggestions: make(map[string]string)}
+	for _, key := range keys {
+		if suggestion := suggestField(key, fields); suggestion != "" {
+			e.Suggestions[key] = suggestion
+		}
+	}
+	return e
+}
+
+func (e *UnknownKeysError) Error() string {
+	parts := make([]string, 0, len(e.Keys))
+	for _, key := range e.Keys {
+		if suggestion, ok := e.Suggestions[key]; ok {
+			parts = append(parts, fmt.Sprintf("'%s' (did you mean '%s'?)", key, suggestion))
+		} else {
+			parts = append(parts, fmt.Sprintf("'%s'", key))
+		}
+	}
+	return "unknown keys: " + strings.Join(parts, ", ")
+}
+
+// MissingFieldsError is returned when the source has no key for fields tagged `required`.
+type MissingFieldsError struct {
+	// Fields lists the effective names of the missing fields in sorted order.
+	Fields []string
+}
+
+// newMissingFieldsError builds a *MissingFieldsError for the effective names in fields.
+func newMissingFieldsError(fields []string) *MissingFieldsError {
+	return &MissingFieldsError{Fields: slices.Sorted(slices.Values(fields))}
+}
+
+func (e *MissingFieldsError) Error() string {
+	return "missing required fields: '" + strings.Join(e.Fields, "', '") + "'"
+}
+
+// VersionAmbiguityError is returned when several fields are tagged with versions of the same
+// key but no API version was selected with WithAPIVersion.
+type VersionAmbiguityError struct {
+	Key    string
+	Fields []string
+}
+
+func (e *VersionAmbiguityError) Error() string {
+	return fmt.Sprintf("fields %s are versions of key %q but no API version is set",
+		strings.Join(e.Fields, ", "), e.Key)
+}
+
+// StrictTypeMismatchError is returned in strict type mode when the kind of a source value
+// differs from the kind of its destination.
+type StrictTypeMismatchError struct {
+	SrcKind reflect.Kind
+	DstKind reflect.Kind
+	Field   string
+}
+
+func (e *StrictTypeMismatchError) Error() string {
+	return fmt.Sprintf("strict types: cannot assign %s to %s field %q", e.SrcKind, e.DstKind, e.Field)
+}
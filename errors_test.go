@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeErrorPath(t *testing.T) {
+	type Address struct {
+		Zip int
+	}
+	type User struct {
+		Name      string `gomap:"name"`
+		Address   Address
+		Addresses []Address
+		ByLabel   map[string]*Address
+		Tags      []int
+		Secret    []byte `gomap:",hex"`
+	}
+
+	tests := []struct {
+		name  string
+		src   map[string]interface{}
+		field string
+		path  []string
+	}{
+		{"top-level field", map[string]interface{}{"name": 1}, "Name", []string{"Name"}},
+		{
+			"nested struct",
+			map[string]interface{}{"Address": map[string]interface{}{"Zip": "x"}},
+			"Zip",
+			[]string{"Address", "Zip"},
+		},
+		{
+			"slice element",
+			map[string]interface{}{"Addresses": []interface{}{map[string]interface{}{}, map[string]interface{}{"Zip": "x"}}},
+			"Zip",
+			[]string{"Addresses", "1", "Zip"},
+		},
+		{
+			"map value",
+			map[string]interface{}{"ByLabel": map[string]interface{}{"home": map[string]interface{}{"Zip": "x"}}},
+			"Zip",
+			[]string{"ByLabel", "home", "Zip"},
+		},
+		{"scalar slice element", map[string]interface{}{"Tags": []interface{}{1, "x"}}, "Tags", []string{"Tags"}},
+		{"tagged bytes", map[string]interface{}{"Secret": "zz"}, "Secret", []string{"Secret"}},
+	}
+	for _, tt := range tests {
+		for name, d := range map[string]*Decoder{"default": NewDecoder(), "collect errors": NewDecoder(WithCollectErrors())} {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				var dst User
+				err := d.Decode(tt.src, &dst)
+				var decodeErr *DecodeError
+				if !errors.As(err, &decodeErr) {
+					t.Fatalf("expected *DecodeError, got %v", err)
+				}
+				if decodeErr.Field != tt.field || !reflect.DeepEqual(decodeErr.Path, tt.path) {
+					t.Errorf("expected field %q at %v, got %q at %v", tt.field, tt.path, decodeErr.Field, decodeErr.Path)
+				}
+				if want := strings.Join(tt.path, "."); !strings.Contains(err.Error(), `"`+want+`"`) {
+					t.Errorf("expected error to mention %q, got %v", want, err)
+				}
+			})
+		}
+	}
+
+	t.Run("nested unknown keys", func(t *testing.T) {
+		var dst User
+		err := NewDecoder(WithStrictMode()).Decode(map[string]interface{}{"Address": map[string]interface{}{"Zp": 1}}, &dst)
+		var (
+			decodeErr *DecodeError
+			unknown   *UnknownKeysError
+		)
+		if !errors.As(err, &decodeErr) || !errors.As(err, &unknown) {
+			t.Fatalf("expected *DecodeError wrapping *UnknownKeysError, got %v", err)
+		}
+		if !reflect.DeepEqual(decodeErr.Path, []string{"Address"}) {
+			t.Errorf("unexpected path %v", decodeErr.Path)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+// fastPathEnabled reports whether no option requires per-key or per-value processing, so that
+// assignMapFast may bypass the reflection path for plain values.
+func (d *Decoder) fastPathEnabled() bool {
+	o := &d.opts
+	return o.Coercions == nil && o.Expander == nil && o.Normalization == nil &&
+		o.KeyTransformer == nil && !o.SourceTracking && !o.FieldTiming && len(d.typeHooks) == 0
+}
+
+// assignMapFast decodes a map[string]interface{} by ranging over it directly and assigning
+// string, int, float64, bool and nil values with type assertions, which avoids the reflect.Value
+// boxing of MapKeys and MapIndex. Other values fall back to decodeField.
+func (d *Decoder) assignMapFast(m map[string]interface{}, fieldsMap map[string]structField) error {
+	var (
+		errs    DecodeErrors
+		unknown []string
+	)
+	for key, v := range m {
+		field, ok := d.lookupField(fieldsMap, key)
+		if !ok {
+			d.unknownKey(key, fieldsMap, &unknown)
+			continue
+		}
+		if err := d.collectError(&errs, field, d.assignFast(key, v, field)); err != nil {
+			return err
+		}
+	}
+	return d.structResult(errs, unknown, fieldsMap)
+}
+
+// assignMapCached is assignMapFast for the struct value out, resolving keys through the cached
+// metadata of its type instead of a per-call field map, so that decoding plain values into a
+// struct does not allocate.
+func (d *Decoder) assignMapCached(m map[string]interface{}, out reflect.Value, meta *structMeta) error {
+	var (
+		errs    DecodeErrors
+		unknown []string
+	)
+	for key, v := range m {
+		fm, ok := meta.fields[key]
+		if !ok {
+			if d.opts.Warn != nil || d.opts.Strict {
+				d.unknownKey(key, meta.structFields(out), &unknown)
+			}
+			continue
+		}
+		field := fm.field(out)
+		if err := d.collectError(&errs, field, d.assignFast(key, v, field)); err != nil {
+			return err
+		}
+	}
+	if len(unknown) == 0 {
+		return errs.orNil()
+	}
+	return d.structResult(errs, unknown, meta.structFields(out))
+}
+
+// assignFast decodes the value v of the source key `key` into field, using fastAssign when possible.
+func (d *Decoder) assignFast(key string, v interface{}, field structField) error {
+	if !d.decodable(field) {
+		return nil
+	}
+	field.allocate()
+	if !field.value.CanSet() {
+		return unsettableField(field)
+	}
+	if d.fastAssign(field.value, v) {
+		return nil
+	}
+	return d.decodeField(key, reflect.ValueOf(v), field)
+}
+
+// fastAssign stores v in dst when dst is exactly the matching predeclared type.
+// It reports false when the value needs the reflection path.
+func (d *Decoder) fastAssign(dst reflect.Value, v interface{}) bool {
+	if v == nil {
+		// sql.Scanner destinations decode nil themselves.
+		scanner := reflect.TypeFor[sql.Scanner]()
+		isScanner := dst.Type().Implements(scanner) || reflect.PointerTo(dst.Type()).Implements(scanner)
+		return d.opts.NilSource == NilSourceSkip && !d.patching && !isScanner
+	}
+
+	switch x := v.(type) {
+	case string:
+		if dst.Type() == reflect.TypeFor[string]() {
+			dst.SetString(x)
+			return true
+		}
+	case int:
+		t := dst.Type()
+		if t == reflect.TypeFor[int]() || (t == reflect.TypeFor[int64]() && !d.opts.StrictTypes) {
+			dst.SetInt(int64(x))
+			return true
+		}
+	case float64:
+		if dst.Type() == reflect.TypeFor[float64]() {
+			dst.SetFloat(x)
+			return true
+		}
+	case bool:
+		if dst.Type() == reflect.TypeFor[bool]() {
+			dst.SetBool(x)
+			return true
+		}
+	}
+	return false
+}
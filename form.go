@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+// formTagKey is the struct tag key giving form sources an alternative name for a field.
+const formTagKey = "form"
+
+// formValues marks the values of a key read from a map[string][]string source, such as
+// url.Values, so that assignField can apply form semantics.
+type formValues []string
+
+// isFormSource reports whether the map data holds HTTP form values.
+func isFormSource(data reflect.Value) bool {
+	return data.Type().Elem() == reflect.TypeFor[[]string]()
+}
+
+// DecodeForm decodes HTTP form values into the struct pointed to by out with the default
+// options, see Decoder.DecodeValues.
+func DecodeForm(form map[string][]string, out interface{}) error {
+	return NewDecoder().DecodeValues(form, out)
+}
+
+// DecodeValues decodes URL query or form values into the struct pointed to by out. Slice
+// fields receive every value of their key; other fields receive the first one. Values are
+// parsed into numeric and bool fields as with WithWeakDecode. Keys match fields by their
+// effective name or by the name of a `form:"name"` tag.
+func (d *Decoder) DecodeValues(v url.Values, out interface{}) error {
+	return d.Decode(map[string][]string(v), out)
+}
+
+// renameFormKeys returns a copy of the form source data in which keys naming a field through
+// its `form` tag, as listed in formNames, are replaced by the field's effective name. Keys that
+// already use the effective name take precedence.
+func renameFormKeys(data reflect.Value, formNames map[string]string) reflect.Value {
+	renamed := make(map[string][]string, data.Len())
+	iter := data.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		if name, ok := formNames[key]; ok {
+			if !data.MapIndex(reflect.ValueOf(name).Convert(data.Type().Key())).IsValid() {
+				key = name
+			}
+		}
+		renamed[key], _ = iter.Value().Interface().([]string)
+	}
+	return reflect.ValueOf(renamed)
+}
+
+// assignFormValues assigns the values of a form key to field. A slice destination
+// receives every value, converted one by one; any other destination receives the first value,
+// and a warning is reported if there are more.
+func (d *Decoder) assignFormValues(values formValues, field structField) error {
+	dst := field.value
+	for dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			if err := d.allocatePtr(dst); err != nil {
+				return err
+			}
+		}
+		dst = dst.Elem()
+	}
+
+	if dst.Kind() == reflect.Slice && dst.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(dst.Type(), len(values), len(values))
+		for i, v := range values {
+			if err := d.assignFormString(slice.Index(i), v); err != nil {
+				return fmt.Errorf("field %q, value %d: %w", field.name, i, err)
+			}
+		}
+		dst.Set(slice)
+		return nil
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+	if len(values) > 1 && d.opts.Warn != nil {
+		d.opts.Warn(fmt.Sprintf("field '%s' received %d form values, using the first", field.name, len(values)))
+	}
+	if err := d.assignFormString(dst, values[0]); err != nil {
+		return fmt.Errorf("field %q: %w", field.name, err)
+	}
+	return nil
+}
+
+// assignFormString parses the form value s into dst. Durations are parsed with
+// time.ParseDuration and other bool and numeric kinds as in weak mode; other destinations go
+// through i2sReflect.
+func (d *Decoder) assignFormString(dst reflect.Value, s string) error {
+	if handled, err := d.runTypeHook(reflect.ValueOf(s), dst); handled {
+		return err
+	}
+	if handled, err := d.assignDuration(reflect.ValueOf(s), dst); handled {
+		return err
+	}
+	if handled, err := parseString(dst, s); handled {
+		return err
+	}
+	return d.i2sReflect(reflect.ValueOf(s), dst)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeForm(t *testing.T) {
+	type Search struct {
+		Query  string `gomap:"q"`
+		Page   int    `gomap:"page"`
+		Exact  bool   `gomap:"exact"`
+		Tags   []string
+		IDs    []int64 `gomap:"id"`
+		Limit  *uint
+		Ignore string
+	}
+
+	t.Run("scalar, slice and missing keys", func(t *testing.T) {
+		form := url.Values{
+			"q":     {"gopher"},
+			"page":  {"2"},
+			"exact": {"true"},
+			"Tags":  {"a", "b"},
+			"id":    {"1", "2", "3"},
+			"Limit": {"10"},
+		}
+
+		dst := Search{Ignore: "keep"}
+		if err := DecodeForm(form, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		limit := uint(10)
+		want := Search{
+			Query:  "gopher",
+			Page:   2,
+			Exact:  true,
+			Tags:   []string{"a", "b"},
+			IDs:    []int64{1, 2, 3},
+			Limit:  &limit,
+			Ignore: "keep",
+		}
+		if !reflect.DeepEqual(dst, want) {
+			t.Errorf("expected %+v, got %+v", want, dst)
+		}
+	})
+
+	t.Run("multiple values for scalar", func(t *testing.T) {
+		var warnings []string
+		d := NewDecoder(WithWarningHandler(func(msg string) { warnings = append(warnings, msg) }))
+
+		var dst Search
+		if err := d.Decode(map[string][]string{"q": {"first", "second"}}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Query != "first" {
+			t.Errorf("expected first value, got %q", dst.Query)
+		}
+		if len(warnings) != 1 || !strings.Contains(warnings[0], "Query") {
+			t.Errorf("expected a warning about Query, got %v", warnings)
+		}
+	})
+
+	t.Run("invalid element", func(t *testing.T) {
+		var dst Search
+		err := DecodeForm(map[string][]string{"id": {"1", "x"}}, &dst)
+		if err == nil || !strings.Contains(err.Error(), "value 1") {
+			t.Errorf("expected error for second element, got %v", err)
+		}
+	})
+}
+
+func TestDecodeValues(t *testing.T) {
+	type Filter struct {
+		Status   string   `form:"status"`
+		Sort     string   `gomap:"sort" form:"order"`
+		PageSize int      `form:"page_size"`
+		Labels   []string `form:"label"`
+		Verbose  bool
+		Debug    bool `form:"-"`
+	}
+
+	t.Run("form tag aliases", func(t *testing.T) {
+		vals := url.Values{
+			"status":    {"open"},
+			"order":     {"asc"},
+			"page_size": {"50"},
+			"label":     {"bug", "ui"},
+			"Verbose":   {"yes"},
+		}
+		var dst Filter
+		if err := NewDecoder().DecodeValues(vals, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := Filter{Status: "open", Sort: "asc", PageSize: 50, Labels: []string{"bug", "ui"}, Verbose: true}
+		if !reflect.DeepEqual(dst, want) {
+			t.Errorf("got %+v, want %+v", dst, want)
+		}
+	})
+
+	t.Run("effective names still match", func(t *testing.T) {
+		var dst Filter
+		if err := NewDecoder().DecodeValues(url.Values{"sort": {"desc"}, "Status": {"closed"}}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Sort != "desc" || dst.Status != "closed" {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("effective name takes precedence over the alias", func(t *testing.T) {
+		var dst Filter
+		if err := NewDecoder().DecodeValues(url.Values{"sort": {"desc"}, "order": {"asc"}}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Sort != "desc" {
+			t.Errorf("expected sort to win, got %q", dst.Sort)
+		}
+	})
+
+	t.Run("aliases satisfy required fields", func(t *testing.T) {
+		type Login struct {
+			User string `gomap:"user,required" form:"username"`
+		}
+		var dst Login
+		if err := NewDecoder().DecodeValues(url.Values{"username": {"gopher"}}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.User != "gopher" {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		var dst Filter
+		if err := NewDecoder().DecodeValues(url.Values{"page_size": {"many"}}, &dst); err == nil {
+			t.Error("expected error for a non-numeric page size")
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// fuzzDestinations are the destination types FuzzAssignSimpleValue decodes into.
+var fuzzDestinations = []reflect.Type{ //nolint:gochecknoglobals // fuzz test fixture
+	reflect.TypeFor[int](), reflect.TypeFor[int8](), reflect.TypeFor[int16](), reflect.TypeFor[int32](),
+	reflect.TypeFor[int64](), reflect.TypeFor[uint](), reflect.TypeFor[uint8](), reflect.TypeFor[uint16](),
+	reflect.TypeFor[uint32](), reflect.TypeFor[uint64](), reflect.TypeFor[float32](), reflect.TypeFor[float64](),
+	reflect.TypeFor[complex64](), reflect.TypeFor[bool](), reflect.TypeFor[string](), reflect.TypeFor[[]byte](),
+	reflect.TypeFor[*int](), reflect.TypeFor[*string](),
+}
+
+func FuzzAssignSimpleValue(f *testing.F) {
+	// source kinds: 0 int64, 1 uint64, 2 float64, 3 string, 4 bool.
+	f.Add(uint8(0), uint8(0), int64(42), uint64(0), 0.0, "", false)
+	f.Add(uint8(1), uint8(0), int64(0), uint64(math.MaxUint64), 0.0, "", false)
+	f.Add(uint8(0), uint8(6), int64(-1), uint64(0), 0.0, "", false)
+	f.Add(uint8(2), uint8(1), int64(0), uint64(0), 200.0, "", false)
+	f.Add(uint8(2), uint8(0), int64(0), uint64(0), 1.5, "", false)
+	f.Add(uint8(2), uint8(10), int64(0), uint64(0), 1e39, "", false)
+	f.Add(uint8(2), uint8(0), int64(0), uint64(0), math.NaN(), "", false)
+	f.Add(uint8(3), uint8(14), int64(0), uint64(0), 0.0, "test", false)
+	f.Add(uint8(3), uint8(0), int64(0), uint64(0), 0.0, "42", false)
+	f.Add(uint8(3), uint8(15), int64(0), uint64(0), 0.0, "aGk=", false)
+	f.Add(uint8(4), uint8(13), int64(0), uint64(0), 0.0, "", true)
+
+	f.Fuzz(func(t *testing.T, srcKind, dstIndex uint8, i int64, u uint64, fl float64, s string, b bool) {
+		var src interface{}
+		switch srcKind % 5 {
+		case 0:
+			src = i
+		case 1:
+			src = u
+		case 2:
+			src = fl
+		case 3:
+			src = s
+		default:
+			src = b
+		}
+		dst := reflect.New(fuzzDestinations[int(dstIndex)%len(fuzzDestinations)]).Elem()
+
+		if err := NewDecoder().assignSimpleValue(dst, reflect.ValueOf(src)); err != nil {
+			return
+		}
+		// a successful integer conversion must preserve the value.
+		switch v := src.(type) {
+		case int64:
+			switch dst.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				if dst.Int() != v {
+					t.Errorf("%d stored as %d in %s", v, dst.Int(), dst.Type())
+				}
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				if v < 0 || dst.Uint() != uint64(v) {
+					t.Errorf("%d stored as %d in %s", v, dst.Uint(), dst.Type())
+				}
+			}
+		case uint64:
+			switch dst.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				if dst.Int() < 0 || uint64(dst.Int()) != v {
+					t.Errorf("%d stored as %d in %s", v, dst.Int(), dst.Type())
+				}
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				if dst.Uint() != v {
+					t.Errorf("%d stored as %d in %s", v, dst.Uint(), dst.Type())
+				}
+			}
+		}
+	})
+}
+
+func FuzzI2S(f *testing.F) {
+	f.Add([]byte(`{"KeyInt": 42, "KeyFloat": 3.14, "KeyBool": true, "KeyString": "test"}`))
+	f.Add([]byte(`{"KeyInt": "42"}`))
+	f.Add([]byte(`{"KeyInt": 1.5}`))
+	f.Add([]byte(`{"KeyInt": 1e300, "KeyFloat": -1e300}`))
+	f.Add([]byte(`{"KeyString": null, "KeyBool": 1}`))
+	f.Add([]byte(`{"SubSimple": {"KeyInt": 1}, "ManySimple": [{"KeyString": "a"}, null], "Blocks": [{"ID": 7}]}`))
+	f.Add([]byte(`{"ManySimple": {"KeyInt": 1}}`))
+	f.Add([]byte(`{"Blocks": [[1, 2]], "SubSimple": []}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var src map[string]interface{}
+		if err := json.Unmarshal(data, &src); err != nil || src == nil {
+			return
+		}
+
+		var simple Simple
+		if err := i2s(src, &simple); err == nil {
+			if n, ok := src["KeyInt"].(float64); ok && float64(simple.KeyInt) != n {
+				t.Errorf("KeyInt %v decoded as %d", n, simple.KeyInt)
+			}
+			if s, ok := src["KeyString"].(string); ok && simple.KeyString != s {
+				t.Errorf("KeyString %q decoded as %q", s, simple.KeyString)
+			}
+		}
+
+		var complexDst Complex
+		if err := i2s(src, &complexDst); err == nil {
+			if items, ok := src["ManySimple"].([]interface{}); ok && len(complexDst.ManySimple) != len(items) {
+				t.Errorf("ManySimple has %d elements, decoded %d", len(items), len(complexDst.ManySimple))
+			}
+		}
+	})
+}
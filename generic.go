@@ -0,0 +1,46 @@
+package main
+
+// Decode decodes data into a new value of type T with the default options, sparing callers
+// the destination variable and the pointer argument of MapToStruct:
+//
+//	user, err := Decode[User](src)
+func Decode[T any](data interface{}) (T, error) {
+	var out T
+	err := i2s(data, &out)
+	return out, err
+}
+
+// DecodeTyped is the generic form of d.Decode. Go does not allow type parameters on methods,
+// so the decoder is passed explicitly:
+//
+//	user, err := DecodeTyped[User](NewDecoder(WithStrictMode()), src)
+func DecodeTyped[T any](d *Decoder, data interface{}) (T, error) {
+	var out T
+	err := d.Decode(data, &out)
+	return out, err
+}
+
+// DecodeSlice decodes a slice of source values, such as a []interface{} of maps, into a []T.
+func DecodeSlice[T any](data interface{}) ([]T, error) {
+	var out []T
+	err := i2s(data, &out)
+	return out, err
+}
+
+// DeepCopy returns a deep copy of the struct, or pointer to struct, src made with Copy and the
+// default options. Unexported fields and fields excluded by tags are not copied.
+func DeepCopy[T any](src T) (T, error) {
+	var dst T
+	err := NewDecoder().Copy(src, &dst)
+	return dst, err
+}
+
+// MustDecode is like Decode but panics if data cannot be decoded. It is intended for
+// sources known to be valid, such as static configuration in tests.
+func MustDecode[T any](data interface{}) T {
+	out, err := Decode[T](data)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"math/big"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGenericDecode(t *testing.T) {
+	type User struct {
+		ID   int
+		Name string
+	}
+
+	src := map[string]interface{}{"ID": 1, "Name": "john"}
+
+	t.Run("Decode", func(t *testing.T) {
+		user, err := Decode[User](src)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if user != (User{ID: 1, Name: "john"}) {
+			t.Errorf("unexpected result: %+v", user)
+		}
+	})
+
+	t.Run("Decode pointer type", func(t *testing.T) {
+		user, err := Decode[*User](src)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if user == nil || user.Name != "john" {
+			t.Errorf("unexpected result: %+v", user)
+		}
+	})
+
+	t.Run("DecodeTyped", func(t *testing.T) {
+		_, err := DecodeTyped[User](NewDecoder(WithStrictMode()), map[string]interface{}{"Nmae": "x"})
+		if err == nil {
+			t.Error("expected decoder options to apply")
+		}
+	})
+
+	t.Run("DecodeSlice", func(t *testing.T) {
+		users, err := DecodeSlice[User]([]interface{}{src, map[string]interface{}{"ID": 2}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []User{{ID: 1, Name: "john"}, {ID: 2}}
+		if !reflect.DeepEqual(users, want) {
+			t.Errorf("expected %+v, got %+v", want, users)
+		}
+	})
+
+	t.Run("MustDecode", func(t *testing.T) {
+		if user := MustDecode[User](src); user.ID != 1 {
+			t.Errorf("unexpected result: %+v", user)
+		}
+
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic")
+			}
+		}()
+		MustDecode[User](map[string]interface{}{"ID": "x"})
+	})
+
+	t.Run("error", func(t *testing.T) {
+		if _, err := Decode[User](map[string]interface{}{"ID": "x"}); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestDeepCopy(t *testing.T) {
+	type Address struct{ City string }
+	type User struct {
+		Name    string
+		Home    *Address
+		Tags    []string
+		Scores  map[string]int
+		Friends []Address
+	}
+
+	src := User{
+		Name:    "john",
+		Home:    &Address{City: "Berlin"},
+		Tags:    []string{"a", "b"},
+		Scores:  map[string]int{"x": 1},
+		Friends: []Address{{City: "Paris"}},
+	}
+
+	dst, err := DeepCopy(src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(dst, src) {
+		t.Fatalf("expected %+v, got %+v", src, dst)
+	}
+
+	src.Home.City = "Rome"
+	src.Tags[0] = "z"
+	src.Scores["x"] = 2
+	src.Friends[0].City = "Oslo"
+	if dst.Home.City != "Berlin" || dst.Tags[0] != "a" || dst.Scores["x"] != 1 || dst.Friends[0].City != "Paris" {
+		t.Errorf("copy shares memory with source: %+v", dst)
+	}
+
+	t.Run("pointer type", func(t *testing.T) {
+		p, err := DeepCopy(&User{Name: "jane", Home: &Address{City: "Oslo"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p == nil || p.Name != "jane" || p.Home.City != "Oslo" {
+			t.Errorf("unexpected result: %+v", p)
+		}
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		if _, err := DeepCopy(42); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("values without exported fields", func(t *testing.T) {
+		type Leaves struct {
+			N    *big.Int
+			F    big.Float
+			Link url.URL
+			At   time.Time
+			Addr netip.Addr
+		}
+		src := Leaves{
+			N:    big.NewInt(42),
+			F:    *big.NewFloat(1.5),
+			Link: url.URL{Scheme: "https", User: url.UserPassword("u", "p"), Host: "x.com", Path: "/a"},
+			At:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Addr: netip.MustParseAddr("10.0.0.1"),
+		}
+		dst, err := DeepCopy(src)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.N == nil || dst.N.Int64() != 42 || dst.F.String() != "1.5" {
+			t.Errorf("big values not copied: %v, %v", dst.N, dst.F.String())
+		}
+		if dst.Link.String() != "https://u:p@x.com/a" {
+			t.Errorf("unexpected URL %q", dst.Link.String())
+		}
+		if !dst.At.Equal(src.At) || dst.Addr != src.Addr {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+
+		src.N.SetInt64(7)
+		if dst.N.Int64() != 42 || dst.Link.User == src.Link.User {
+			t.Error("copy shares memory with source")
+		}
+
+		var out Leaves
+		if err := CopyStruct(&src, &out); err != nil || out.N.Int64() != 7 || out.Link.User.Username() != "u" {
+			t.Errorf("CopyStruct: %v, %+v", err, out)
+		}
+	})
+
+	t.Run("Decoder.Copy", func(t *testing.T) {
+		type Target struct{ Name string }
+		var out Target
+		err := NewDecoder(WithStrictMode()).Copy(User{Name: "x"}, &out)
+		if err == nil {
+			t.Error("expected decoder options to apply")
+		}
+	})
+}
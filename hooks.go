@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+)
+
+// TypeHookFunc decodes data into dst, a settable value of the type the hook was registered for.
+type TypeHookFunc func(data interface{}, dst reflect.Value) error
+
+// RegisterTypeHook makes the decoder call fn instead of its default logic whenever it decodes
+// into a value of type t, in any struct, slice or map. A hook for t also applies to *t
+// destinations, which are allocated first. Registering t again replaces the previous hook.
+func (d *Decoder) RegisterTypeHook(t reflect.Type, fn func(data interface{}, dst reflect.Value) error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	// copy on write, so that calls in progress keep the hooks they started with.
+	hooks := make(map[reflect.Type]TypeHookFunc, len(d.typeHooks)+1)
+	maps.Copy(hooks, d.typeHooks)
+	hooks[t] = fn
+	d.typeHooks = hooks
+}
+
+// RegisterTypeConverter is RegisterTypeHook for hooks that compute a value instead of setting
+// dst themselves: fn receives the raw source value and returns the value to store, which must
+// be assignable to t. A nil result stores the zero value of t.
+func (d *Decoder) RegisterTypeConverter(t reflect.Type, fn func(src interface{}) (interface{}, error)) {
+	d.RegisterTypeHook(t, func(data interface{}, dst reflect.Value) error {
+		result, err := fn(data)
+		if err != nil {
+			return err
+		}
+		if result == nil {
+			dst.SetZero()
+			return nil
+		}
+		value := reflect.ValueOf(result)
+		if !value.Type().AssignableTo(dst.Type()) {
+			return fmt.Errorf("converter returned %s, not assignable to %s", value.Type(), dst.Type())
+		}
+		dst.Set(value)
+		return nil
+	})
+}
+
+// RegisterDecoder is RegisterTypeHook for decoders working on reflect values: fn receives the
+// source value, which is invalid for nil sources, and the settable destination of type t.
+func (d *Decoder) RegisterDecoder(t reflect.Type, fn func(src reflect.Value, dst reflect.Value) error) {
+	d.RegisterTypeHook(t, func(data interface{}, dst reflect.Value) error {
+		return fn(reflect.ValueOf(data), dst)
+	})
+}
+
+// runTypeHook calls the hook registered for the type of out, or of the value out points to.
+// It reports whether a hook handled out.
+func (d *Decoder) runTypeHook(data reflect.Value, out reflect.Value) (bool, error) {
+	if len(d.typeHooks) == 0 {
+		return false, nil
+	}
+
+	hook, ok := d.typeHooks[out.Type()]
+	if !ok && out.Kind() == reflect.Pointer {
+		if hook, ok = d.typeHooks[out.Type().Elem()]; ok {
+			if out.IsNil() {
+				if !out.CanSet() {
+					return false, nil
+				}
+				if err := d.allocatePtr(out); err != nil {
+					return true, err
+				}
+			}
+			out = out.Elem()
+		}
+	}
+	if !ok {
+		return false, nil
+	}
+
+	var src interface{}
+	if data.IsValid() {
+		src = data.Interface()
+	}
+	if err := hook(src, out); err != nil {
+		return true, fmt.Errorf("hook for %s: %w", out.Type(), err)
+	}
+	return true, nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type Color struct {
+	R, G, B uint8
+}
+
+func parseColorHook(data interface{}, dst reflect.Value) error {
+	name, ok := data.(string)
+	if !ok {
+		return fmt.Errorf("expected color name, got %T", data)
+	}
+	colors := map[string]Color{
+		"red":   {R: 255},
+		"green": {G: 255},
+		"blue":  {B: 255},
+	}
+	c, ok := colors[name]
+	if !ok {
+		return fmt.Errorf("unknown color %q", name)
+	}
+	dst.Set(reflect.ValueOf(c))
+	return nil
+}
+
+func TestRegisterTypeHook(t *testing.T) {
+	type Theme struct {
+		Primary   Color
+		Secondary *Color
+		Palette   []Color
+	}
+
+	d := NewDecoder()
+	d.RegisterTypeHook(reflect.TypeFor[Color](), parseColorHook)
+
+	src := map[string]interface{}{
+		"Primary":   "red",
+		"Secondary": "green",
+		"Palette":   []interface{}{"blue", "red"},
+	}
+
+	var dst Theme
+	if err := d.Decode(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Primary != (Color{R: 255}) {
+		t.Errorf("unexpected Primary: %+v", dst.Primary)
+	}
+	if dst.Secondary == nil || *dst.Secondary != (Color{G: 255}) {
+		t.Errorf("unexpected Secondary: %+v", dst.Secondary)
+	}
+	if len(dst.Palette) != 2 || dst.Palette[0] != (Color{B: 255}) {
+		t.Errorf("unexpected Palette: %+v", dst.Palette)
+	}
+
+	t.Run("hook error", func(t *testing.T) {
+		var dst Theme
+		err := d.Decode(map[string]interface{}{"Primary": "purple"}, &dst)
+		if err == nil {
+			t.Error("expected hook error")
+		}
+	})
+
+	t.Run("hook overrides named basic type", func(t *testing.T) {
+		type Status string
+		type Job struct {
+			State Status
+		}
+		d := NewDecoder()
+		d.RegisterTypeHook(reflect.TypeFor[Status](), func(data interface{}, dst reflect.Value) error {
+			dst.SetString("hooked:" + fmt.Sprint(data))
+			return nil
+		})
+		var job Job
+		if err := d.Decode(map[string]interface{}{"State": 1}, &job); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if job.State != "hooked:1" {
+			t.Errorf("expected hook to take precedence, got %q", job.State)
+		}
+	})
+
+	t.Run("without hook", func(t *testing.T) {
+		var dst Theme
+		if err := NewDecoder().Decode(map[string]interface{}{"Primary": "red"}, &dst); err == nil {
+			t.Error("expected error without registered hook")
+		}
+	})
+}
+
+func TestTypeHookOnPredeclaredType(t *testing.T) {
+	d := NewDecoder()
+	d.RegisterTypeHook(reflect.TypeFor[string](), func(data interface{}, dst reflect.Value) error {
+		dst.SetString(fmt.Sprintf("<%v>", data))
+		return nil
+	})
+
+	var dst Simple
+	if err := d.Decode(map[string]interface{}{"KeyString": "x"}, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.KeyString != "<x>" {
+		t.Errorf("expected hook to run on the map fast path, got %q", dst.KeyString)
+	}
+}
+
+func TestRegisterTypeConverter(t *testing.T) {
+	type Secret string
+	type Credentials struct {
+		User     string
+		Password Secret
+		Token    *Secret
+	}
+
+	d := NewDecoder()
+	d.RegisterTypeConverter(reflect.TypeFor[Secret](), func(src interface{}) (interface{}, error) {
+		s, ok := src.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string, got %T", src)
+		}
+		if s == "" {
+			return nil, nil //nolint:nilnil // a nil result stores the zero value
+		}
+		// "decrypt" by reversing the string.
+		r := []rune(s)
+		for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+			r[i], r[j] = r[j], r[i]
+		}
+		return Secret(r), nil
+	})
+
+	var dst Credentials
+	src := map[string]interface{}{"User": "admin", "Password": "terces", "Token": "nekot"}
+	if err := d.Decode(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.User != "admin" || dst.Password != "secret" || dst.Token == nil || *dst.Token != "token" {
+		t.Errorf("unexpected result: %+v", dst)
+	}
+
+	t.Run("nil result stores zero value", func(t *testing.T) {
+		dst := Credentials{Password: "old"}
+		if err := d.Decode(map[string]interface{}{"Password": ""}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Password != "" {
+			t.Errorf("expected zero value, got %q", dst.Password)
+		}
+	})
+
+	t.Run("converter error", func(t *testing.T) {
+		var dst Credentials
+		if err := d.Decode(map[string]interface{}{"Password": 42}, &dst); err == nil {
+			t.Error("expected converter error")
+		}
+	})
+
+	t.Run("result of the wrong type", func(t *testing.T) {
+		d := NewDecoder()
+		d.RegisterTypeConverter(reflect.TypeFor[Secret](), func(src interface{}) (interface{}, error) {
+			return 42, nil
+		})
+		var dst Credentials
+		if err := d.Decode(map[string]interface{}{"Password": "x"}, &dst); err == nil {
+			t.Error("expected error for unassignable result")
+		}
+	})
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	type Point struct{ X, Y int }
+	type Shape struct {
+		Name   string
+		Origin Point
+		Points []Point
+	}
+
+	d := NewDecoder()
+	d.RegisterDecoder(reflect.TypeFor[Point](), func(src reflect.Value, dst reflect.Value) error {
+		if src.Kind() != reflect.String {
+			return fmt.Errorf("expected string, got %v", src.Kind())
+		}
+		var p Point
+		if _, err := fmt.Sscanf(src.String(), "%d,%d", &p.X, &p.Y); err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(p))
+		return nil
+	})
+
+	var dst Shape
+	src := map[string]interface{}{"Name": "line", "Origin": "1,2", "Points": []interface{}{"3,4", "5,6"}}
+	if err := d.Decode(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Shape{Name: "line", Origin: Point{1, 2}, Points: []Point{{3, 4}, {5, 6}}}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("expected %+v, got %+v", want, dst)
+	}
+
+	t.Run("decoder error", func(t *testing.T) {
+		var dst Shape
+		if err := d.Decode(map[string]interface{}{"Origin": map[string]interface{}{"X": 1}}, &dst); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+)
+
+// DecodeForm parses the query and form body of r with r.ParseForm and decodes the result into
+// out, see DecodeValues.
+func (d *Decoder) DecodeForm(r *http.Request, out interface{}) error {
+	if err := r.ParseForm(); err != nil {
+		return d.wrapError(out, fmt.Errorf("parsing form: %w", err))
+	}
+	return d.DecodeValues(r.Form, out)
+}
+
+// DecodeMultipartForm parses the multipart/form-data body of r with r.ParseMultipartForm,
+// keeping up to maxMemory bytes of file parts in memory, and decodes it into the struct
+// pointed to by out. Values are decoded as by DecodeValues. Uploaded files are assigned to
+// fields of type *multipart.FileHeader, which receive the first file of their key, and
+// []*multipart.FileHeader, which receive all of them; the headers are stored as is, so their
+// Open method reads the uploaded content. File fields are matched by name like any other
+// field but are not covered by the `required` and `default` tag options.
+func (d *Decoder) DecodeMultipartForm(r *http.Request, maxMemory int64, out interface{}) error {
+	if err := r.ParseMultipartForm(maxMemory); err != nil {
+		return d.wrapError(out, fmt.Errorf("parsing multipart form: %w", err))
+	}
+	if err := d.DecodeValues(r.Form, out); err != nil {
+		return err
+	}
+	return d.wrapError(out, d.assignFiles(r.MultipartForm.File, out))
+}
+
+// assignFiles stores the uploaded files of a multipart form in the file fields of the struct
+// pointed to by out. Keys matching no file field are ignored.
+func (d *Decoder) assignFiles(files map[string][]*multipart.FileHeader, out interface{}) error {
+	s := dereferencePtr(reflect.ValueOf(out))
+	if s.Kind() != reflect.Struct {
+		return fmt.Errorf("expected struct, got %s", s.Kind())
+	}
+	fieldsMap, err := d.mapStructFieldsByName(s)
+	if err != nil {
+		return err
+	}
+	formNames := d.structMeta(s.Type()).formNames
+
+	for key, headers := range files {
+		field, ok := d.lookupField(fieldsMap, key)
+		if !ok {
+			field, ok = fieldsMap[formNames[key]]
+		}
+		if !ok || !d.decodable(field) || len(headers) == 0 {
+			continue
+		}
+
+		switch field.typ() {
+		case reflect.TypeFor[*multipart.FileHeader]():
+			if field.allocate(); field.value.CanSet() {
+				field.value.Set(reflect.ValueOf(headers[0]))
+			}
+		case reflect.TypeFor[[]*multipart.FileHeader]():
+			if field.allocate(); field.value.CanSet() {
+				field.value.Set(reflect.ValueOf(headers))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecoderDecodeForm(t *testing.T) {
+	type Comment struct {
+		PostID int    `form:"post_id"`
+		Body   string `gomap:"body"`
+		Notify bool   `gomap:"notify"`
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/comments?post_id=42", strings.NewReader("body=hi+there&notify=1"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var dst Comment
+	if err := NewDecoder().DecodeForm(req, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst != (Comment{PostID: 42, Body: "hi there", Notify: true}) {
+		t.Errorf("unexpected result: %+v", dst)
+	}
+
+	bad := httptest.NewRequest(http.MethodGet, "/comments?post_id=%zz", nil)
+	if err := NewDecoder().DecodeForm(bad, &dst); err == nil {
+		t.Error("expected error for a malformed query")
+	}
+}
+
+func TestDecodeMultipartForm(t *testing.T) {
+	type Upload struct {
+		Title       string                  `gomap:"title"`
+		Avatar      *multipart.FileHeader   `gomap:"avatar"`
+		Attachments []*multipart.FileHeader `form:"attachment"`
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if err := w.WriteField("title", "report"); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []struct{ field, name, content string }{
+		{"avatar", "me.png", "png"},
+		{"attachment", "a.txt", "first"},
+		{"attachment", "b.txt", "second"},
+	} {
+		part, err := w.CreateFormFile(f.field, f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := part.Write([]byte(f.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	var dst Upload
+	if err := NewDecoder().DecodeMultipartForm(req, 1<<20, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Title != "report" {
+		t.Errorf("unexpected Title %q", dst.Title)
+	}
+	if dst.Avatar == nil || dst.Avatar.Filename != "me.png" {
+		t.Fatalf("unexpected Avatar: %+v", dst.Avatar)
+	}
+	if len(dst.Attachments) != 2 || dst.Attachments[1].Filename != "b.txt" {
+		t.Fatalf("unexpected Attachments: %+v", dst.Attachments)
+	}
+
+	f, err := dst.Attachments[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil || string(content) != "first" {
+		t.Errorf("expected the uploaded content, got %q (%v)", content, err)
+	}
+
+	t.Run("not multipart", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("title=x"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if err := NewDecoder().DecodeMultipartForm(req, 1<<20, &dst); err == nil {
+			t.Error("expected error for a non-multipart body")
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+)
+
+// ExtraKeys returns the sorted list of keys in data that have no corresponding field in proto.
+// Unlike a strict decode it never fails on unknown keys; it returns an error only if proto
+// is not a struct or a pointer to a struct.
+func ExtraKeys(data map[string]interface{}, proto interface{}) ([]string, error) {
+	fields, err := NewDecoder().mapStructFieldsByName(reflect.ValueOf(proto))
+	if err != nil {
+		return nil, err
+	}
+
+	extra := []string{}
+	for key := range data {
+		if _, ok := fields[key]; !ok {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+
+	return extra, nil
+}
+
+// DiscoverMapping is a development aid that maps every key of data to the struct field name
+// of proto it would populate or most likely refers to. Exactly matching keys map to their
+// field; keys too different from every field map to "". It returns nil if proto is not a
+// struct or a pointer to a struct.
+func DiscoverMapping(data map[string]interface{}, proto interface{}) map[string]string {
+	fields, err := NewDecoder().mapStructFieldsByName(reflect.ValueOf(proto))
+	if err != nil {
+		return nil
+	}
+
+	mapping := make(map[string]string, len(data))
+	for key := range data {
+		if _, ok := fields[key]; ok {
+			mapping[key] = key
+			continue
+		}
+		mapping[key] = suggestField(key, fields)
+	}
+	return mapping
+}
+
+// MatchReport describes how the keys of a source map line up with the fields of a struct.
+type MatchReport struct {
+	Matched   []MatchedKey
+	Unmatched []string
+}
+
+// MatchedKey describes a source key that populates a struct field.
+type MatchedKey struct {
+	SourceKey string
+	FieldName string
+	// TypeConversion is the conversion applied to the value, e.g. "float64→int64", or ""
+	// when the source value already has the field's type.
+	TypeConversion string
+}
+
+// Coverage reports which keys of data would populate a field of proto under the decoder's
+// options and which would be ignored, together with the type conversion each match implies.
+// Both lists are sorted by source key.
+func (d *Decoder) Coverage(data map[string]interface{}, proto interface{}) (*MatchReport, error) {
+	fields, err := d.mapStructFieldsByName(reflect.ValueOf(proto))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	report := &MatchReport{Matched: []MatchedKey{}, Unmatched: []string{}}
+	for _, key := range keys {
+		field, ok := d.lookupField(fields, key)
+		if !ok || !d.decodable(field) {
+			report.Unmatched = append(report.Unmatched, key)
+			continue
+		}
+		report.Matched = append(report.Matched, MatchedKey{
+			SourceKey:      key,
+			FieldName:      field.name,
+			TypeConversion: typeConversion(data[key], field.typ()),
+		})
+	}
+	return report, nil
+}
+
+// typeConversion describes the conversion from the type of src to dst, or "" if none is needed.
+func typeConversion(src interface{}, dst reflect.Type) string {
+	if src == nil {
+		return "nil→" + dst.String()
+	}
+	if srcType := reflect.TypeOf(src); srcType != dst {
+		return srcType.String() + "→" + dst.String()
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtraKeys(t *testing.T) {
+	t.Run("missing fields", func(t *testing.T) {
+		src := map[string]interface{}{
+			"KeyInt":    1,
+			"KeyString": "a",
+			"Zeta":      true,
+			"Alpha":     2,
+		}
+		extra, err := ExtraKeys(src, Simple{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(extra, []string{"Alpha", "Zeta"}) {
+			t.Errorf("unexpected extra keys: %v", extra)
+		}
+	})
+
+	t.Run("all keys match", func(t *testing.T) {
+		src := map[string]interface{}{"KeyInt": 1, "KeyBool": true}
+		extra, err := ExtraKeys(src, &Simple{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(extra) != 0 {
+			t.Errorf("expected no extra keys, got %v", extra)
+		}
+	})
+
+	t.Run("non-struct proto", func(t *testing.T) {
+		_, err := ExtraKeys(map[string]interface{}{"a": 1}, 42)
+		if err == nil {
+			t.Error("expected error for non-struct proto")
+		}
+	})
+}
+
+func TestDiscoverMapping(t *testing.T) {
+	type User struct {
+		UserID int `gomap:"user_id"`
+		Email  string
+		Name   string
+	}
+
+	src := map[string]interface{}{
+		"user_id": 1,
+		"emial":   "a@b.c",
+		"name":    "john",
+		"address": "somewhere",
+	}
+
+	want := map[string]string{
+		"user_id": "user_id",
+		"emial":   "Email",
+		"name":    "Name",
+		"address": "",
+	}
+	if got := DiscoverMapping(src, &User{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiscoverMapping() = %v, want %v", got, want)
+	}
+
+	if got := DiscoverMapping(src, 42); got != nil {
+		t.Errorf("expected nil for non-struct proto, got %v", got)
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	type Event struct {
+		ID       int64 `gomap:"id"`
+		Name     string
+		Internal string `gomap:",decode_ignore"`
+		Score    float64
+	}
+
+	src := map[string]interface{}{
+		"id":       42.0,
+		"Name":     "launch",
+		"Internal": "x",
+		"Score":    nil,
+		"Unknown":  true,
+	}
+
+	report, err := NewDecoder().Coverage(src, Event{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantMatched := []MatchedKey{
+		{SourceKey: "Name", FieldName: "Name", TypeConversion: ""},
+		{SourceKey: "Score", FieldName: "Score", TypeConversion: "nil→float64"},
+		{SourceKey: "id", FieldName: "ID", TypeConversion: "float64→int64"},
+	}
+	if !reflect.DeepEqual(report.Matched, wantMatched) {
+		t.Errorf("Matched = %+v, want %+v", report.Matched, wantMatched)
+	}
+	if !reflect.DeepEqual(report.Unmatched, []string{"Internal", "Unknown"}) {
+		t.Errorf("Unmatched = %v", report.Unmatched)
+	}
+
+	if _, err := NewDecoder().Coverage(src, "not a struct"); err == nil {
+		t.Error("expected error for non-struct proto")
+	}
+
+	t.Run("key transformer", func(t *testing.T) {
+		src := map[string]interface{}{"name": "launch", "score": 1.5, "unknown_key": true}
+		report, err := NewDecoder(WithKeyTransformer(SnakeToCamel)).Coverage(src, Event{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		wantMatched := []MatchedKey{
+			{SourceKey: "name", FieldName: "Name"},
+			{SourceKey: "score", FieldName: "Score"},
+		}
+		if !reflect.DeepEqual(report.Matched, wantMatched) {
+			t.Errorf("Matched = %+v, want %+v", report.Matched, wantMatched)
+		}
+		if !reflect.DeepEqual(report.Unmatched, []string{"unknown_key"}) {
+			t.Errorf("Unmatched = %v", report.Unmatched)
+		}
+	})
+}
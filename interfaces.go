@@ -0,0 +1,74 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// interfaceTarget returns out, or its address, as a value implementing iface. A nil pointer
+// destination is allocated first. It reports false if out cannot be used as an iface.
+func interfaceTarget(out reflect.Value, iface reflect.Type) (interface{}, bool) {
+	switch {
+	case out.Kind() == reflect.Pointer && out.Type().Implements(iface):
+		if out.IsNil() {
+			if !out.CanSet() {
+				return nil, false
+			}
+			out.Set(reflect.New(out.Type().Elem()))
+		}
+		return out.Interface(), true
+	case out.CanAddr() && reflect.PointerTo(out.Type()).Implements(iface):
+		return out.Addr().Interface(), true
+	default:
+		return nil, false
+	}
+}
+
+// scanInto lets destinations implementing sql.Scanner decode themselves by calling
+// Scan with the raw source value. It reports whether out was handled.
+func scanInto(data reflect.Value, out reflect.Value) (bool, error) {
+	var src interface{}
+	if data.IsValid() {
+		src = data.Interface()
+	}
+
+	// a nil source leaves optional scanner fields nil.
+	if src == nil && out.Kind() == reflect.Pointer {
+		return false, nil
+	}
+
+	target, ok := interfaceTarget(out, reflect.TypeFor[sql.Scanner]())
+	if !ok {
+		return false, nil
+	}
+
+	scanner, _ := target.(sql.Scanner)
+	if err := scanner.Scan(src); err != nil {
+		return true, fmt.Errorf("scanning into %s: %w", out.Type(), err)
+	}
+	return true, nil
+}
+
+// sscanInto parses a string source with fmt.Sscan when weak decoding is enabled and the
+// destination implements fmt.Scanner. It reports whether out was handled.
+func (d *Decoder) sscanInto(data reflect.Value, out reflect.Value) (bool, error) {
+	if !d.opts.WeakDecode {
+		return false, nil
+	}
+
+	src := dereferencePtr(data)
+	if src.Kind() != reflect.String {
+		return false, nil
+	}
+
+	target, ok := interfaceTarget(out, reflect.TypeFor[fmt.Scanner]())
+	if !ok {
+		return false, nil
+	}
+
+	if _, err := fmt.Sscan(src.String(), target); err != nil {
+		return true, fmt.Errorf("scanning %q into %s: %w", src.String(), out.Type(), err)
+	}
+	return true, nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// upperScanner is a user-defined sql.Scanner that upper-cases string sources.
+type upperScanner struct {
+	Value string
+	Null  bool
+}
+
+func (u *upperScanner) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		u.Value, u.Null = "", true
+	case string:
+		u.Value, u.Null = strings.ToUpper(v), false
+	default:
+		return errors.New("unsupported source")
+	}
+	return nil
+}
+
+func TestScannerDestinations(t *testing.T) {
+	type Row struct {
+		Name    sql.NullString
+		Age     sql.NullInt64
+		Code    upperScanner
+		CodePtr *upperScanner
+	}
+
+	t.Run("non-nil sources", func(t *testing.T) {
+		src := map[string]interface{}{
+			"Name":    "john",
+			"Age":     int64(42),
+			"Code":    "abc",
+			"CodePtr": "xyz",
+		}
+		var dst Row
+		if err := i2s(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !dst.Name.Valid || dst.Name.String != "john" {
+			t.Errorf("unexpected Name: %+v", dst.Name)
+		}
+		if !dst.Age.Valid || dst.Age.Int64 != 42 {
+			t.Errorf("unexpected Age: %+v", dst.Age)
+		}
+		if dst.Code.Value != "ABC" || dst.CodePtr == nil || dst.CodePtr.Value != "XYZ" {
+			t.Errorf("unexpected Code: %+v %+v", dst.Code, dst.CodePtr)
+		}
+	})
+
+	t.Run("nil sources", func(t *testing.T) {
+		src := map[string]interface{}{
+			"Name":    nil,
+			"Age":     nil,
+			"Code":    nil,
+			"CodePtr": nil,
+		}
+		dst := Row{Name: sql.NullString{String: "old", Valid: true}}
+		if err := i2s(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Name.Valid || dst.Age.Valid {
+			t.Errorf("expected invalid null values, got %+v %+v", dst.Name, dst.Age)
+		}
+		if !dst.Code.Null || dst.CodePtr != nil {
+			t.Errorf("unexpected Code: %+v %+v", dst.Code, dst.CodePtr)
+		}
+	})
+
+	t.Run("scan error", func(t *testing.T) {
+		var dst Row
+		if err := i2s(map[string]interface{}{"Code": 1}, &dst); err == nil {
+			t.Error("expected scan error")
+		}
+	})
+}
+
+// semver is a user-defined fmt.Scanner parsing "major.minor.patch".
+type semver struct {
+	Major, Minor, Patch int
+}
+
+func (v *semver) Scan(state fmt.ScanState, _ rune) error {
+	tok, err := state.Token(true, nil)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Sscanf(string(tok), "%d.%d.%d", &v.Major, &v.Minor, &v.Patch)
+	return err
+}
+
+func TestFmtScannerDestinations(t *testing.T) {
+	type Release struct {
+		Version semver
+		Size    big.Int
+		Hash    *big.Int
+	}
+
+	src := map[string]interface{}{
+		"Version": "1.2.3",
+		"Size":    "123456789012345678901234567890",
+		"Hash":    "42",
+	}
+
+	t.Run("weak mode", func(t *testing.T) {
+		var dst Release
+		if err := NewDecoder(WithWeakDecode()).Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Version != (semver{1, 2, 3}) {
+			t.Errorf("unexpected Version: %+v", dst.Version)
+		}
+		if dst.Size.String() != "123456789012345678901234567890" {
+			t.Errorf("unexpected Size: %s", dst.Size.String())
+		}
+		if dst.Hash == nil || dst.Hash.Int64() != 42 {
+			t.Errorf("unexpected Hash: %v", dst.Hash)
+		}
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		var dst Release
+		err := NewDecoder(WithWeakDecode()).Decode(map[string]interface{}{"Size": "abc"}, &dst)
+		if err == nil {
+			t.Error("expected scan error")
+		}
+	})
+
+	t.Run("strict mode", func(t *testing.T) {
+		var dst Release
+		if err := NewDecoder().Decode(map[string]interface{}{"Version": "1.2.3"}, &dst); err == nil {
+			t.Error("expected error without weak mode")
+		}
+	})
+}
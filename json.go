@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// DecodeJSON unmarshals the JSON document data and decodes the result into out with the
+// decoder's options. Objects decode into structs and maps, arrays into slices. Malformed JSON
+// is reported as a *JSONSyntaxError.
+func (d *Decoder) DecodeJSON(data []byte, out interface{}) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return d.wrapError(out, jsonError(err))
+	}
+	return d.Decode(v, out)
+}
+
+// DecodeJSONReader reads one JSON value from r and decodes it into out like DecodeJSON.
+// Numbers are read as json.Number, so large integers keep their precision; interface{}
+// destinations receive them as json.Number too.
+func (d *Decoder) DecodeJSONReader(r io.Reader, out interface{}) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return d.wrapError(out, jsonError(err))
+	}
+	return d.Decode(v, out)
+}
+
+// DecodeJSONStream decodes every JSON value of the stream r into out in turn, as
+// DecodeJSONReader does, and calls fn after each one. out is reset to its zero value before
+// each value, so fn only sees the fields of the latest one. Decoding stops at the end of the
+// stream, or with the first error from decoding or from fn.
+func (d *Decoder) DecodeJSONStream(r io.Reader, out interface{}, fn func() error) error {
+	target := reflect.ValueOf(out)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return d.wrapError(out, errors.New("out must be a non-nil pointer"))
+	}
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	for i := 0; ; i++ {
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return d.wrapError(out, fmt.Errorf("value %d: %w", i, jsonError(err)))
+		}
+
+		target.Elem().SetZero()
+		if err := d.decodeSession(v, out); err != nil {
+			return d.wrapError(out, fmt.Errorf("value %d: %w", i, err))
+		}
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+}
+
+// jsonError translates the errors of encoding/json into the package's error types.
+func jsonError(err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return &JSONSyntaxError{Offset: syntaxErr.Offset, Err: err}
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		if typeErr.Field == "" {
+			return &DecodeError{Err: err}
+		}
+		path := strings.Split(typeErr.Field, ".")
+		return &DecodeError{Field: path[len(path)-1], Path: path, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	type Item struct {
+		ID    int       `gomap:"id"`
+		Name  string    `gomap:"name"`
+		Tags  []string  `gomap:"tags"`
+		Added time.Time `gomap:"added"`
+	}
+
+	t.Run("object", func(t *testing.T) {
+		var dst Item
+		data := []byte(`{"id": 7, "name": "pen", "tags": ["a", "b"], "added": "2024-01-02T03:04:05Z"}`)
+		if err := NewDecoder().DecodeJSON(data, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.ID != 7 || dst.Name != "pen" || len(dst.Tags) != 2 || dst.Added.Year() != 2024 {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("array", func(t *testing.T) {
+		var dst []Item
+		if err := NewDecoder().DecodeJSON([]byte(`[{"id": 1}, {"id": 2}]`), &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(dst) != 2 || dst[1].ID != 2 {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("options apply", func(t *testing.T) {
+		var dst Item
+		d := NewDecoder(WithStrictMode(), WithTimeFormat(time.DateOnly))
+		if err := d.DecodeJSON([]byte(`{"added": "2024-05-06"}`), &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Added.Month() != time.May {
+			t.Errorf("expected the custom time format, got %v", dst.Added)
+		}
+		var unknown *UnknownKeysError
+		if err := d.DecodeJSON([]byte(`{"nmae": "x"}`), &dst); !errors.As(err, &unknown) {
+			t.Errorf("expected UnknownKeysError, got %v", err)
+		}
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		var dst Item
+		err := NewDecoder().DecodeJSON([]byte(`{"id": 1,}`), &dst)
+		var syntaxErr *JSONSyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("expected JSONSyntaxError, got %v", err)
+		}
+		if syntaxErr.Offset != 10 {
+			t.Errorf("unexpected offset %d", syntaxErr.Offset)
+		}
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		var dst Item
+		if err := NewDecoder().DecodeJSON([]byte(`{"id": "seven"}`), &dst); err == nil {
+			t.Error("expected error for a string id")
+		}
+	})
+}
+
+func TestDecodeJSONReader(t *testing.T) {
+	type Account struct {
+		ID      int64       `gomap:"id"`
+		Balance float64     `gomap:"balance"`
+		Meta    interface{} `gomap:"meta"`
+	}
+
+	var dst Account
+	r := strings.NewReader(`{"id": 9007199254740993, "balance": 12.5, "meta": 3}`)
+	if err := NewDecoder().DecodeJSONReader(r, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.ID != 9007199254740993 || dst.Balance != 12.5 || dst.Meta != json.Number("3") {
+		t.Errorf("unexpected result: %+v", dst)
+	}
+
+	var syntaxErr *JSONSyntaxError
+	if err := NewDecoder().DecodeJSONReader(strings.NewReader(`{"id": }`), &dst); !errors.As(err, &syntaxErr) {
+		t.Errorf("expected JSONSyntaxError, got %v", err)
+	}
+}
+
+func TestDecodeJSONStream(t *testing.T) {
+	type Event struct {
+		Type string `gomap:"type"`
+		Seq  int    `gomap:"seq"`
+	}
+
+	t.Run("every value", func(t *testing.T) {
+		r := strings.NewReader(`{"type": "start", "seq": 1}
+{"seq": 2}
+{"type": "stop", "seq": 3}`)
+		var (
+			event Event
+			got   []Event
+		)
+		err := NewDecoder().DecodeJSONStream(r, &event, func() error {
+			got = append(got, event)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []Event{{"start", 1}, {"", 2}, {"stop", 3}}
+		if !slices.Equal(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("callback error stops decoding", func(t *testing.T) {
+		stop := errors.New("stop")
+		calls := 0
+		var event Event
+		err := NewDecoder().DecodeJSONStream(strings.NewReader(`{"seq": 1} {"seq": 2}`), &event, func() error {
+			calls++
+			return stop
+		})
+		if !errors.Is(err, stop) || calls != 1 {
+			t.Errorf("expected to stop after the first value, got %v after %d calls", err, calls)
+		}
+	})
+
+	t.Run("decode error names the value", func(t *testing.T) {
+		var event Event
+		err := NewDecoder().DecodeJSONStream(strings.NewReader(`{"seq": 1} {"seq": "x"}`), &event, func() error {
+			return nil
+		})
+		if err == nil || !strings.HasPrefix(err.Error(), "value 1:") {
+			t.Errorf("expected error for value 1, got %v", err)
+		}
+	})
+}
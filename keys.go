@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// lookupField returns the field decoded from the source key `key`. Keys are matched exactly
+// first; otherwise the key is passed through the configured KeyTransformer and matched against
+// the names of untagged fields, and against those names passed through the transformer too, so
+// that both SnakeToCamel and CamelToSnake work. Tag names are used verbatim. A transformed key
+// matching several fields matches none.
+func (d *Decoder) lookupField(fields map[string]structField, key string) (structField, bool) {
+	if field, ok := fields[key]; ok || d.opts.KeyTransformer == nil {
+		return field, ok
+	}
+
+	transformed := d.opts.KeyTransformer(key)
+	if field, ok := fields[transformed]; ok {
+		return field, field.tag.name == ""
+	}
+
+	var (
+		match structField
+		found bool
+	)
+	for name, field := range fields {
+		if field.tag.name != "" || d.opts.KeyTransformer(name) != transformed {
+			continue
+		}
+		if found {
+			return structField{}, false
+		}
+		match, found = field, true
+	}
+	return match, found
+}
+
+// SnakeToCamel converts a snake_case key to CamelCase, e.g. "user_name" to "UserName".
+// It is meant for use with WithKeyTransformer.
+func SnakeToCamel(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	upper := true
+	for _, r := range s {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// CamelToSnake converts a CamelCase name to snake_case, keeping acronyms together, e.g.
+// "UserName" to "user_name" and "HTTPServerID" to "http_server_id".
+func CamelToSnake(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 4)
+
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
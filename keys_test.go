@@ -0,0 +1,94 @@
+package main
+
+import "testing"
+
+func TestCaseTransformers(t *testing.T) {
+	tests := []struct {
+		snake, camel string
+	}{
+		{"user_name", "UserName"},
+		{"id", "Id"},
+		{"created_at_2", "CreatedAt2"},
+		{"name", "Name"},
+	}
+	for _, tt := range tests {
+		if got := SnakeToCamel(tt.snake); got != tt.camel {
+			t.Errorf("SnakeToCamel(%q) = %q, want %q", tt.snake, got, tt.camel)
+		}
+	}
+
+	for in, want := range map[string]string{
+		"UserName":     "user_name",
+		"HTTPServerID": "http_server_id",
+		"userID":       "user_id",
+		"Name":         "name",
+		"Field2Value":  "field2_value",
+	} {
+		if got := CamelToSnake(in); got != want {
+			t.Errorf("CamelToSnake(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWithKeyTransformer(t *testing.T) {
+	type User struct {
+		UserName  string
+		CreatedAt string
+		Email     string `gomap:"mail"`
+	}
+
+	t.Run("snake to camel", func(t *testing.T) {
+		src := map[string]interface{}{"user_name": "john", "created_at": "now", "mail": "j@x"}
+		var dst User
+		if err := NewDecoder(WithKeyTransformer(SnakeToCamel)).Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst != (User{UserName: "john", CreatedAt: "now", Email: "j@x"}) {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("camel to snake", func(t *testing.T) {
+		src := map[string]interface{}{"userName": "john", "created_at": "now"}
+		var dst User
+		d := NewDecoder(WithKeyTransformer(CamelToSnake), WithStrictMode())
+		if err := d.Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst != (User{UserName: "john", CreatedAt: "now"}) {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("ambiguous transformed key", func(t *testing.T) {
+		type Pair struct {
+			UserID string
+			UserId string //nolint:revive // collides with UserID once converted
+		}
+		var dst Pair
+		d := NewDecoder(WithKeyTransformer(CamelToSnake), WithStrictMode())
+		if err := d.Decode(map[string]interface{}{"userId": "x"}, &dst); err == nil {
+			t.Errorf("expected ambiguous key to be unknown, got %+v", dst)
+		}
+	})
+
+	t.Run("tag names bypass the transformer", func(t *testing.T) {
+		src := map[string]interface{}{"email": "j@x"}
+		var dst User
+		d := NewDecoder(WithKeyTransformer(SnakeToCamel), WithStrictMode())
+		if err := d.Decode(src, &dst); err == nil {
+			t.Errorf("expected 'email' not to match the tagged field, got %+v", dst)
+		}
+	})
+
+	t.Run("exact names still match", func(t *testing.T) {
+		var dst User
+		d := NewDecoder(WithKeyTransformer(SnakeToCamel))
+		if err := d.Decode(map[string]interface{}{"UserName": "john"}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.UserName != "john" {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// PreDecoder is implemented by structs that inspect or rewrite their source map before any
+// of their fields is assigned.
+type PreDecoder interface {
+	// BeforeDecode receives a copy of the source map; changes to it are decoded in place of
+	// the original entries.
+	BeforeDecode(data map[string]interface{}) error
+}
+
+// PostDecoder is implemented by structs that validate or complete themselves once all of
+// their fields are assigned, e.g. to check constraints spanning several fields.
+type PostDecoder interface {
+	// AfterDecode is called on the populated struct. It is not called if decoding failed.
+	AfterDecode() error
+}
+
+// beforeDecode calls BeforeDecode on the addressable struct s with a copy of the string-keyed
+// map data, and returns the map to decode from.
+func beforeDecode(data reflect.Value, s reflect.Value) (reflect.Value, error) {
+	m := copyStringMap(data)
+	pre, _ := s.Addr().Interface().(PreDecoder)
+	if err := pre.BeforeDecode(m); err != nil {
+		return reflect.Value{}, fmt.Errorf("before decoding %s: %w", s.Type(), err)
+	}
+	return reflect.ValueOf(m), nil
+}
+
+// afterDecode calls AfterDecode on the addressable struct s.
+func afterDecode(s reflect.Value) error {
+	post, _ := s.Addr().Interface().(PostDecoder)
+	if err := post.AfterDecode(); err != nil {
+		return fmt.Errorf("after decoding %s: %w", s.Type(), err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type dateRange struct {
+	From  int
+	To    int
+	Label string
+	calls []string
+}
+
+func (r *dateRange) BeforeDecode(data map[string]interface{}) error {
+	r.calls = append(r.calls, "before")
+	if label, ok := data["Label"].(string); ok {
+		data["Label"] = strings.ToUpper(label)
+	}
+	if _, ok := data["Span"]; ok {
+		return errors.New("span is no longer supported")
+	}
+	return nil
+}
+
+func (r *dateRange) AfterDecode() error {
+	r.calls = append(r.calls, "after")
+	if r.From > r.To {
+		return errors.New("From must not be after To")
+	}
+	return nil
+}
+
+func TestDecodeLifecycleHooks(t *testing.T) {
+	t.Run("before and after", func(t *testing.T) {
+		src := map[string]interface{}{"From": 1, "To": 5, "Label": "week"}
+		var dst dateRange
+		if err := i2s(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Label != "WEEK" || dst.From != 1 || dst.To != 5 {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+		if strings.Join(dst.calls, ",") != "before,after" {
+			t.Errorf("unexpected hook calls: %v", dst.calls)
+		}
+		if src["Label"] != "week" {
+			t.Errorf("BeforeDecode modified the caller's map: %v", src)
+		}
+	})
+
+	t.Run("before decode error", func(t *testing.T) {
+		var dst dateRange
+		err := i2s(map[string]interface{}{"Span": 3, "From": 1}, &dst)
+		if err == nil || !strings.Contains(err.Error(), "span is no longer supported") {
+			t.Fatalf("expected BeforeDecode error, got %v", err)
+		}
+		if dst.From != 0 {
+			t.Errorf("expected no field to be assigned, got %+v", dst)
+		}
+	})
+
+	t.Run("after decode validation", func(t *testing.T) {
+		var dst dateRange
+		err := i2s(map[string]interface{}{"From": 9, "To": 2}, &dst)
+		if err == nil || !strings.Contains(err.Error(), "From must not be after To") {
+			t.Fatalf("expected AfterDecode error, got %v", err)
+		}
+	})
+
+	t.Run("not called after a field error", func(t *testing.T) {
+		var dst dateRange
+		if err := i2s(map[string]interface{}{"From": "x"}, &dst); err == nil {
+			t.Fatal("expected field error")
+		}
+		if strings.Join(dst.calls, ",") != "before" {
+			t.Errorf("unexpected hook calls: %v", dst.calls)
+		}
+	})
+
+	t.Run("nested struct", func(t *testing.T) {
+		type Report struct {
+			Period *dateRange
+			Others []dateRange
+		}
+		var dst Report
+		src := map[string]interface{}{
+			"Period": map[string]interface{}{"From": 1, "To": 2, "Label": "q1"},
+			"Others": []interface{}{map[string]interface{}{"From": 3, "To": 4, "Label": "q2"}},
+		}
+		if err := i2s(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Period == nil || dst.Period.Label != "Q1" || len(dst.Others) != 1 || dst.Others[0].Label != "Q2" {
+			t.Errorf("expected hooks on nested structs, got %+v", dst)
+		}
+
+		src = map[string]interface{}{"Others": []interface{}{map[string]interface{}{"From": 3, "To": 1}}}
+		err := i2s(src, &dst)
+		if err == nil || !strings.Contains(err.Error(), "From must not be after To") {
+			t.Fatalf("expected AfterDecode error from slice element, got %v", err)
+		}
+	})
+}
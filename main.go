@@ -3,15 +3,40 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
 )
 
-// mapStructFieldsByName maps the field names of a struct to their corresponding reflect.Value.
-// It returns an error if the input is not a struct or a pointer to a struct.
-func mapStructFieldsByName(out reflect.Value) (map[string]reflect.Value, error) {
+// structField describes a single struct field together with the options parsed from its tag.
+type structField struct {
+	name  string
+	value reflect.Value
+	tag   fieldTag
+	depth int
+	// owner is the struct value the field was looked up in and index its index sequence there.
+	// value is invalid while the field is promoted through a nil embedded pointer, see allocate.
+	owner reflect.Value
+	index []int
+}
+
+// mapStructFieldsByName maps the effective names of a struct's fields (the tag name when set,
+// otherwise the Go field name) to their corresponding structField. Fields tagged `gomap:"-"`
+// are left out, and the fields of untagged embedded structs are promoted.
+// It returns an error if the input is not a struct or a pointer to a struct, or a
+// *FieldCollisionError if several fields resolve to the same name. Fields sharing a name
+// through `version` tags are narrowed down by selectVersion first.
+// The layout of each struct type is computed once and cached, see structMeta; only the
+// field values are looked up per call.
+func (d *Decoder) mapStructFieldsByName(out reflect.Value) (map[string]structField, error) {
 	if out.Kind() == reflect.Pointer {
 		out = out.Elem()
 	}
@@ -20,43 +45,138 @@ func mapStructFieldsByName(out reflect.Value) (map[string]reflect.Value, error)
 		return nil, fmt.Errorf("expected struct, got %s", out.Kind().String())
 	}
 
-	mp := make(map[string]reflect.Value)
+	meta := d.structMeta(out.Type())
+	mp := make(map[string]structField, len(meta.fields)+len(meta.grouped))
+	for name, fm := range meta.fields {
+		mp[name] = fm.field(out)
+	}
+
+	for name, group := range meta.grouped {
+		fields := make([]structField, len(group))
+		for i, fm := range group {
+			fields[i] = fm.field(out)
+		}
+		fields, err := d.selectVersion(name, fields)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 {
+			continue
+		}
 
-	for i := range out.NumField() {
-		fieldName := out.Type().Field(i).Name
-		mp[fieldName] = out.Field(i)
+		field, err := d.resolveCollision(name, fields)
+		if err != nil {
+			return nil, err
+		}
+		mp[name] = field
 	}
 
 	return mp, nil
 }
 
+// selectVersion filters the fields sharing an effective name by their `version` tag option.
+// With WithAPIVersion, versioned fields outside the requested version are dropped; without
+// it, several versioned fields for the same name are reported as a *VersionAmbiguityError.
+func (d *Decoder) selectVersion(name string, fields []structField) ([]structField, error) {
+	selected := make([]structField, 0, len(fields))
+	var versioned []string
+
+	for _, field := range fields {
+		r, ok, err := field.tag.versions()
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.name, err)
+		}
+		if ok {
+			versioned = append(versioned, field.name)
+			if d.opts.APIVersion != nil && !r.contains(*d.opts.APIVersion) {
+				continue
+			}
+		}
+		selected = append(selected, field)
+	}
+
+	if d.opts.APIVersion == nil && len(versioned) > 1 {
+		return nil, &VersionAmbiguityError{Key: name, Fields: versioned}
+	}
+	return selected, nil
+}
+
+// resolveCollision picks the field that owns an effective name according to the configured
+// CollisionStrategy, or returns a *FieldCollisionError when no single field can be chosen.
+func (d *Decoder) resolveCollision(name string, fields []structField) (structField, error) {
+	if len(fields) == 1 {
+		return fields[0], nil
+	}
+
+	if d.opts.CollisionStrategy == CollisionPreferOuter {
+		// as in Go's own promotion rules, the shallowest field wins unless it is ambiguous.
+		outer := slices.MinFunc(fields, func(a, b structField) int { return a.depth - b.depth })
+		shallowest := 0
+		for _, f := range fields {
+			if f.depth == outer.depth {
+				shallowest++
+			}
+		}
+		if shallowest == 1 {
+			return outer, nil
+		}
+	}
+
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		names = append(names, f.name)
+	}
+	return structField{}, &FieldCollisionError{EffectiveName: name, Fields: names}
+}
+
 // assignSimpleValue assigns a simple value (int, float, bool, string, complex) from src to dst,
-// handling type conversion where appropriate. Returns an error on incompatible types.
-func assignSimpleValue(dst reflect.Value, src reflect.Value) error {
+// handling type conversion where appropriate. Coercions registered with WithCoercionRegistry
+// take precedence over the built-in conversions. Returns an error on incompatible types.
+func (d *Decoder) assignSimpleValue(dst reflect.Value, src reflect.Value) error {
 	dstType := dst.Type().Kind()
 	srcType := src.Type().Kind()
 
+	if fn, ok := d.opts.Coercions.lookup(srcType, dstType); ok {
+		return d.applyCoercion(fn, dst, src)
+	}
+
+	if d.opts.StrictTypes && dstType != reflect.Pointer && srcType != dstType {
+		return &StrictTypeMismatchError{SrcKind: srcType, DstKind: dstType}
+	}
+	if src.Type() == reflect.TypeFor[json.Number]() {
+		if handled, err := assignJSONNumber(dst, json.Number(src.String())); handled {
+			return err
+		}
+	}
+	if d.opts.WeakDecode && dstType != srcType {
+		if handled, err := weakAssign(dst, src); handled {
+			return err
+		}
+	}
+
 	// convert source to destination type if compatible.
 	switch dstType {
 	case reflect.Pointer:
 		if dst.IsNil() {
-			dst.Set(reflect.New(dst.Type().Elem()))
+			if err := d.allocatePtr(dst); err != nil {
+				return err
+			}
 		}
-		return assignSimpleValue(dst.Elem(), src)
+		return d.assignSimpleValue(dst.Elem(), src)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		switch srcType {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			dst.SetInt(src.Int())
+			return d.setInt(dst, src.Int())
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 			val := src.Uint()
 			if val > math.MaxInt64 {
 				return errors.New("value too large to convert uint to int64")
 			}
-			dst.SetInt(int64(val))
+			return d.setInt(dst, int64(val))
 		case reflect.Float32, reflect.Float64:
-			dst.SetInt(int64(src.Float()))
+			return d.setIntFromFloat(dst, src.Float())
 		default:
-			return fmt.Errorf("cannot assign value of type %s to int field %q", srcType, dst.Type().Name())
+			return fmt.Errorf("cannot assign value of type %s to int field %q", src.Type(), dst.Type().String())
 		}
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		switch srcType {
@@ -65,13 +185,13 @@ func assignSimpleValue(dst reflect.Value, src reflect.Value) error {
 			if val < 0 {
 				return errors.New("cannot convert negative int to uint")
 			}
-			dst.SetUint(uint64(val))
+			return d.setUint(dst, uint64(val))
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			dst.SetUint(src.Uint())
+			return d.setUint(dst, src.Uint())
 		case reflect.Float32, reflect.Float64:
-			dst.SetUint(uint64(src.Float()))
+			return d.setUintFromFloat(dst, src.Float())
 		default:
-			return fmt.Errorf("cannot assign value of type %s to uint field %q", srcType, dst.Type().Name())
+			return fmt.Errorf("cannot assign value of type %s to uint field %q", src.Type(), dst.Type().String())
 		}
 	case reflect.Float32, reflect.Float64:
 		switch srcType {
@@ -80,28 +200,34 @@ func assignSimpleValue(dst reflect.Value, src reflect.Value) error {
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 			dst.SetFloat(float64(src.Uint()))
 		case reflect.Float32, reflect.Float64:
-			dst.SetFloat(src.Float())
+			return d.setFloat(dst, src.Float())
 		default:
-			return fmt.Errorf("cannot assign value of type %s to float field %q", srcType, dst.Type().Name())
+			return fmt.Errorf("cannot assign value of type %s to float field %q", src.Type(), dst.Type().String())
 		}
 	case reflect.Complex64, reflect.Complex128:
 		if srcType == reflect.Complex64 || srcType == reflect.Complex128 {
 			dst.SetComplex(src.Complex())
 		} else {
-			return fmt.Errorf("cannot assign value of type %s to complex field %q", srcType, dst.Type().Name())
+			return fmt.Errorf("cannot assign value of type %s to complex field %q", src.Type(), dst.Type().String())
 		}
 	case reflect.Bool:
 		if srcType == reflect.Bool {
 			dst.SetBool(src.Bool())
 		} else {
-			return fmt.Errorf("cannot assign value of type %s to bool field %q", srcType, dst.Type().Name())
+			return fmt.Errorf("cannot assign value of type %s to bool field %q", src.Type(), dst.Type().String())
 		}
 	case reflect.String:
 		if srcType == reflect.String {
-			dst.SetString(src.String())
+			dst.SetString(d.opts.stringValue(src.String()))
 		} else {
-			return fmt.Errorf("cannot assign value of type %s to string field %q", srcType, dst.Type().Name())
+			return fmt.Errorf("cannot assign value of type %s to string field %q", src.Type(), dst.Type().String())
+		}
+	case reflect.Slice:
+		// strings are stored in byte slices as their UTF-8 encoding, unless a base64 encoding is set.
+		if srcType == reflect.String && dst.Type().Elem().Kind() == reflect.Uint8 {
+			return d.assignStringBytes(dst, src.String())
 		}
+		return fmt.Errorf("cannot assign value of type %s to field %q", src.Type(), dst.Type().String())
 	default:
 		return fmt.Errorf("unsupported dst type: %s", dstType)
 	}
@@ -115,8 +241,9 @@ func checkIfArrayOrSlice(val reflect.Value) bool {
 }
 
 // allocateAndFillSlice creates a new slice of the same type as dst, fills it by recursively copying
-// elements from src, and sets it to dst. Returns an error if types are incompatible.
-func allocateAndFillSlice(dst reflect.Value, src reflect.Value) error {
+// elements from src, and sets it to dst, or appends it to dst with WithAppendSlice. Returns an
+// error if types are incompatible.
+func (d *Decoder) allocateAndFillSlice(dst reflect.Value, src reflect.Value) error {
 	if !checkIfArrayOrSlice(dst) {
 		return errors.New("dst is not array or slice")
 	}
@@ -124,18 +251,46 @@ func allocateAndFillSlice(dst reflect.Value, src reflect.Value) error {
 		return errors.New("src is not array or slice")
 	}
 
-	dstElemType := dst.Type().Elem()
+	// elements are decoded in place: the elements of a new slice are addressable zero values.
 	newDst := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
-
 	for i := range src.Len() {
-		srcElem := src.Index(i)
-		dstElem := reflect.New(dstElemType).Elem()
-
-		if err := i2sReflect(srcElem, dstElem); err != nil {
+		if err := d.i2sReflect(src.Index(i), newDst.Index(i)); err != nil {
+			if prefixPath(err, strconv.Itoa(i)) {
+				return err
+			}
 			return fmt.Errorf("element %d conversion failed: %w", i, err)
 		}
+	}
+
+	if d.opts.AppendSlice {
+		newDst = reflect.AppendSlice(dst, newDst)
+	}
+	dst.Set(newDst)
+	return nil
+}
+
+// allocateAndFillArray fills a new array of the same type as the array dst with the converted
+// elements of src and sets it to dst. Elements beyond the length of src are zero. Returns an
+// error if src has more elements than dst can hold or if types are incompatible.
+func (d *Decoder) allocateAndFillArray(dst reflect.Value, src reflect.Value) error {
+	if dst.Kind() != reflect.Array {
+		return errors.New("dst is not array")
+	}
+	if !checkIfArrayOrSlice(src) {
+		return errors.New("src is not array or slice")
+	}
+	if src.Len() > dst.Len() {
+		return fmt.Errorf("source has %d elements, more than %s can hold", src.Len(), dst.Type())
+	}
 
-		newDst.Index(i).Set(dstElem)
+	newDst := reflect.New(dst.Type()).Elem()
+	for i := range src.Len() {
+		if err := d.i2sReflect(src.Index(i), newDst.Index(i)); err != nil {
+			if prefixPath(err, strconv.Itoa(i)) {
+				return err
+			}
+			return fmt.Errorf("element %d conversion failed: %w", i, err)
+		}
 	}
 
 	dst.Set(newDst)
@@ -143,16 +298,39 @@ func allocateAndFillSlice(dst reflect.Value, src reflect.Value) error {
 }
 
 // assignArraySliceValue assigns values from a source slice or array to a destination slice or array.
-// It handles deep copying of elements. Returns an error on failure.
-func assignArraySliceValue(dst reflect.Value, src reflect.Value) error {
-	if !checkIfArrayOrSlice(dst) {
+// It handles deep copying of elements; byte slices are copied in one go. Nil pointers to the
+// destination, as in *[]T fields, are allocated first. Returns an error on failure.
+func (d *Decoder) assignArraySliceValue(dst reflect.Value, src reflect.Value) error {
+	if kind := indirectType(dst.Type()).Kind(); kind != reflect.Array && kind != reflect.Slice {
 		return errors.New("dst is not array/slice")
 	}
 	if !checkIfArrayOrSlice(src) {
 		return errors.New("src is not array/lice")
 	}
 
-	err := allocateAndFillSlice(dst, src)
+	for dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			if err := d.allocatePtr(dst); err != nil {
+				return err
+			}
+		}
+		dst = dst.Elem()
+	}
+
+	if isByteSlice(src) && isByteSlice(dst) {
+		if d.opts.AppendSlice {
+			dst.SetBytes(append(dst.Bytes(), src.Bytes()...))
+			return nil
+		}
+		dst.SetBytes(bytes.Clone(src.Bytes()))
+		return nil
+	}
+
+	if dst.Kind() == reflect.Array {
+		return d.allocateAndFillArray(dst, src)
+	}
+
+	err := d.allocateAndFillSlice(dst, src)
 	if err != nil {
 		return err
 	}
@@ -170,41 +348,327 @@ func mapKeyType(data reflect.Value) (reflect.Kind, error) {
 }
 
 // assignMap maps key-value pairs from a map[string]interface{} to fields of a struct.
-// Fields not present in the struct are ignored.
-func assignMap(data reflect.Value, out reflect.Value) error {
+// Fields not present in the struct, tagged with decode_ignore, or outside the groups selected
+// with WithGroups are ignored.
+// Nil pointers to the struct destination, at any level of indirection, are allocated before
+// its fields are assigned.
+// A map[string][]string source is decoded as HTTP form values, see DecodeForm, and maps with
+// interface{} keys are accepted with WithAllowInterfaceMapKeys.
+// Structs implementing PreDecoder and PostDecoder are notified before and after decoding.
+// Fields whose keys are absent receive the value of their `default` tag option, if any.
+func (d *Decoder) assignMap(data reflect.Value, out reflect.Value) error {
 	if data.IsNil() {
 		return nil
 	}
 
-	fieldsMap, err := mapStructFieldsByName(out)
+	mapKeyType, err := mapKeyType(data)
+	if err != nil {
+		return err
+	}
+	if mapKeyType == reflect.Interface && d.opts.AllowInterfaceMapKeys {
+		data, mapKeyType = stringKeyedMap(data), reflect.String
+	}
+	if mapKeyType != reflect.String {
+		return fmt.Errorf("expected map with string key, got %s", mapKeyType.String())
+	}
+
+	if len(d.opts.Preprocessors) > 0 {
+		if data, err = d.preprocess(data); err != nil {
+			return err
+		}
+	}
+
+	if out.Kind() == reflect.Pointer && out.IsNil() && d.opts.LazyOptional &&
+		!d.matchesAnyField(data, indirectType(out.Type())) {
+		return nil
+	}
+	for out.Kind() == reflect.Pointer {
+		if out.IsNil() {
+			if !out.CanSet() {
+				return errors.New("out must be a non-nil pointer")
+			}
+			if err := d.allocatePtr(out); err != nil {
+				return err
+			}
+		}
+		out = out.Elem()
+	}
+
+	s := dereferencePtr(out)
+	var meta *structMeta
+	if s.Kind() == reflect.Struct {
+		meta = d.structMeta(s.Type())
+		if len(meta.formNames) > 0 && isFormSource(data) {
+			data = renameFormKeys(data, meta.formNames)
+		}
+		if meta.preDecoder && s.CanAddr() {
+			if data, err = beforeDecode(data, s); err != nil {
+				return err
+			}
+		}
+	}
+
+	if meta != nil && len(meta.remain) > 0 {
+		if data, err = d.splitRemain(data, s, meta); err != nil {
+			return err
+		}
+	}
+
+	if d.opts.ZeroMissing && !d.merging() {
+		if err := d.zeroMissing(data, out); err != nil {
+			return err
+		}
+	}
+
+	err = d.assignFields(data, out, meta)
+	if meta != nil && meta.defaults && !d.merging() && err == nil {
+		err = d.applyDefaults(data, out)
+	}
+	if meta != nil && meta.required && !d.patching && (err == nil || d.opts.CollectErrors) {
+		err = d.checkRequired(err, data, out)
+	}
 	if err != nil {
 		return err
 	}
+	if meta != nil && meta.postDecoder && s.CanAddr() {
+		return afterDecode(s)
+	}
+	return nil
+}
 
-	mapKeyType, err := mapKeyType(data)
+// assignFields assigns the entries of the string-keyed map data to the fields of the struct
+// out, whose cached metadata is meta, or nil if out is not a struct.
+func (d *Decoder) assignFields(data reflect.Value, out reflect.Value, meta *structMeta) error {
+	m, fast := data.Interface().(map[string]interface{})
+	fast = fast && d.fastPathEnabled()
+	if fast && meta != nil && len(meta.grouped) == 0 {
+		return d.assignMapCached(m, dereferencePtr(out), meta)
+	}
+
+	fieldsMap, err := d.mapStructFieldsByName(out)
 	if err != nil {
 		return err
 	}
-	if mapKeyType != reflect.String {
-		return fmt.Errorf("expected map with string key, got %s", mapKeyType.String())
+
+	if fast {
+		return d.assignMapFast(m, fieldsMap)
 	}
 
+	var (
+		errs    DecodeErrors
+		unknown []string
+	)
+	form := isFormSource(data)
 	for _, key := range data.MapKeys() {
 		value := data.MapIndex(key)
-		outField, ok := fieldsMap[key.String()]
+		if form {
+			value = value.Convert(reflect.TypeFor[formValues]())
+		}
+		outField, ok := d.lookupField(fieldsMap, key.String())
 		if !ok {
+			d.unknownKey(key.String(), fieldsMap, &unknown)
+			continue
+		}
+		if !d.decodable(outField) {
 			continue
 		}
 
-		err = i2sReflect(value, outField)
-		if err != nil {
+		err = d.decodeField(key.String(), value, outField)
+		if err = d.collectError(&errs, outField, err); err != nil {
 			return err
 		}
 	}
 
+	return d.structResult(errs, unknown, fieldsMap)
+}
+
+// preprocess passes a shallow copy of the string-keyed map data through the configured
+// preprocessors in order and returns the result.
+func (d *Decoder) preprocess(data reflect.Value) (reflect.Value, error) {
+	m := copyStringMap(data)
+	for i, fn := range d.opts.Preprocessors {
+		var err error
+		if m, err = fn(m); err != nil {
+			return reflect.Value{}, fmt.Errorf("preprocessor %d: %w", i, err)
+		}
+	}
+	return reflect.ValueOf(m), nil
+}
+
+// copyStringMap returns a shallow copy of the string-keyed map data as a map[string]interface{}.
+func copyStringMap(data reflect.Value) map[string]interface{} {
+	m := make(map[string]interface{}, data.Len())
+	iter := data.MapRange()
+	for iter.Next() {
+		m[iter.Key().String()] = iter.Value().Interface()
+	}
+	return m
+}
+
+// decodeField decodes value, read from the source key `key`, into field and records where
+// the value came from and how long it took when source tracking or field timing is enabled.
+func (d *Decoder) decodeField(key string, value reflect.Value, field structField) error {
+	field.allocate()
+	if !field.value.CanSet() {
+		return unsettableField(field)
+	}
+	if !d.opts.SourceTracking && !d.opts.FieldTiming {
+		return d.assignField(value, field)
+	}
+
+	d.path = append(d.path, field.name)
+	defer func() { d.path = d.path[:len(d.path)-1] }()
+
+	var start time.Time
+	if d.opts.FieldTiming {
+		start = time.Now()
+	}
+
+	err := d.assignField(value, field)
+	if d.opts.FieldTiming {
+		d.recordTiming(time.Since(start))
+	}
+	if err != nil {
+		return err
+	}
+
+	if d.opts.SourceTracking {
+		d.trackSource(key)
+	}
+	return nil
+}
+
+// unsettableField reports that field cannot be assigned, typically because the struct holding
+// it is not addressable or is reached through a nil embedded pointer that cannot be allocated.
+func unsettableField(field structField) error {
+	return fmt.Errorf("field %q cannot be set: the struct holding it is not addressable", field.name)
+}
+
+// assignField assigns value to field, applying the conversions requested by the field's tag
+// before falling back to i2sReflect.
+func (d *Decoder) assignField(value reflect.Value, field structField) error {
+	if value.IsValid() && value.Type() == reflect.TypeFor[formValues]() {
+		return d.assignFormValues(value.Interface().(formValues), field)
+	}
+	if handled, err := decodeTaggedBytes(field, value); handled {
+		return err
+	}
+
+	err := d.i2sReflect(value, field.value)
+	if err != nil && d.opts.StrictTypes {
+		// wrapping errors format their message eagerly, so the named mismatch replaces them.
+		var mismatch *StrictTypeMismatchError
+		if errors.As(err, &mismatch) && mismatch.Field == "" {
+			mismatch.Field = field.name
+			return mismatch
+		}
+	}
+	return err
+}
+
+// collectError handles the error err from decoding field, attributing it to the field with
+// fieldError. Without WithCollectErrors, or once the context of DecodeContext is done, it
+// returns the attributed error so that the caller stops; otherwise it appends it to errs,
+// flattening the errors collected in nested structs, and returns nil.
+func (d *Decoder) collectError(errs *DecodeErrors, field structField, err error) error {
+	if err == nil {
+		return nil
+	}
+	err = fieldError(field, err)
+	if !d.opts.CollectErrors || d.ctxErr() != nil {
+		return err
+	}
+
+	if nested, ok := err.(DecodeErrors); ok { //nolint:errorlint // collected errors are returned unwrapped
+		*errs = append(*errs, nested...)
+		return nil
+	}
+	*errs = append(*errs, err)
+	return nil
+}
+
+// zeroMissing sets the decodable fields of out whose keys are absent from the string-keyed
+// map data to their zero value.
+func (d *Decoder) zeroMissing(data reflect.Value, out reflect.Value) error {
+	fieldsMap, err := d.mapStructFieldsByName(out)
+	if err != nil {
+		return err
+	}
+
+	present := d.presentFields(data, fieldsMap)
+	for _, field := range fieldsMap {
+		// fields behind nil embedded pointers are already zero.
+		if field.value.IsValid() && d.decodable(field) && !present[field.name] {
+			field.value.Set(reflect.Zero(field.value.Type()))
+		}
+	}
 	return nil
 }
 
+// presentFields returns the names of the fields in fieldsMap that a key of the string-keyed
+// map data resolves to.
+func (d *Decoder) presentFields(data reflect.Value, fieldsMap map[string]structField) map[string]bool {
+	present := make(map[string]bool, data.Len())
+	for _, key := range data.MapKeys() {
+		if field, ok := d.lookupField(fieldsMap, key.String()); ok {
+			present[field.name] = true
+		}
+	}
+	return present
+}
+
+// checkRequired adds a *MissingFieldsError to err, the result of assigning the string-keyed
+// map data to out, if keys of fields tagged `required` are absent from data. A key present
+// with a zero value satisfies the tag.
+func (d *Decoder) checkRequired(err error, data reflect.Value, out reflect.Value) error {
+	fieldsMap, mapErr := d.mapStructFieldsByName(out)
+	if mapErr != nil {
+		return mapErr
+	}
+
+	present := d.presentFields(data, fieldsMap)
+	var missing []string
+	for name, field := range fieldsMap {
+		if field.tag.has("required") && d.decodable(field) && !present[field.name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return err
+	}
+
+	missingErr := newMissingFieldsError(missing)
+	if err == nil {
+		return missingErr
+	}
+	if errs, ok := err.(DecodeErrors); ok { //nolint:errorlint // collected errors are returned unwrapped
+		return append(errs, missingErr)
+	}
+	return err
+}
+
+// decodable reports whether a field may be populated from source data under the decoder's options.
+func (d *Decoder) decodable(field structField) bool {
+	return !field.tag.skipDecode() && d.opts.inGroups(field.tag) && d.isSelected(field)
+}
+
+// matchesAnyField reports whether any key of the string-keyed map data would be decoded
+// into a field of the struct type t.
+func (d *Decoder) matchesAnyField(data reflect.Value, t reflect.Type) bool {
+	fieldsMap, err := d.mapStructFieldsByName(reflect.New(t).Elem())
+	if err != nil {
+		// let assignMap report the type mismatch.
+		return true
+	}
+
+	for _, key := range data.MapKeys() {
+		if field, ok := d.lookupField(fieldsMap, key.String()); ok && d.decodable(field) {
+			return true
+		}
+	}
+	return false
+}
+
 // dereferencePtr follows pointer or interface chains to get the underlying non-pointer, non-interface value.
 // If the value is nil, it returns as-is.
 func dereferencePtr(out reflect.Value) reflect.Value {
@@ -222,8 +686,65 @@ func dereferencePtr(out reflect.Value) reflect.Value {
 }
 
 // i2sReflect recursively assigns data from a reflect.Value into a target reflect.Value.
-// Handles basic types, maps, slices/arrays, and interfaces.
-func i2sReflect(data reflect.Value, out reflect.Value) error {
+// Handles basic types, maps, slices/arrays, and interfaces. Type hooks run first; the nullable
+// types of database/sql wrap the decoded value, see assignSQLNull; other destinations
+// implementing sql.Scanner (or fmt.Scanner, for string sources in weak mode) decode themselves;
+// other nil sources are handled by assignNil. time.Time destinations accept strings and Unix
+// timestamps, see assignTime, and time.Duration destinations accept duration strings.
+// Nesting is bounded by WithMaxDepth and self-referencing sources are rejected, see descend.
+// Every call first checks the context of DecodeContext.
+func (d *Decoder) i2sReflect(data reflect.Value, out reflect.Value) error {
+	if err := d.ctxErr(); err != nil {
+		return err
+	}
+	nested, depthErr := d.descend(data)
+	if depthErr != nil {
+		return depthErr
+	}
+	if nested {
+		defer d.ascend()
+	}
+
+	if handled, err := d.runTypeHook(data, out); handled {
+		return err
+	}
+	if handled, err := d.assignSQLNull(data, out); handled {
+		return err
+	}
+	if handled, err := scanInto(data, out); handled {
+		return err
+	}
+	if handled, err := d.sscanInto(data, out); handled {
+		return err
+	}
+
+	if !data.IsValid() || (data.Kind() == reflect.Interface && data.IsNil()) {
+		return d.assignNil(out)
+	}
+
+	if target, ok := interfaceDestination(out); ok {
+		return d.assignInterface(data, target)
+	}
+
+	if handled, err := d.assignTime(data, out); handled {
+		return err
+	}
+	if handled, err := d.assignDuration(data, out); handled {
+		return err
+	}
+	if handled, err := d.assignBigInt(data, out); handled {
+		return err
+	}
+	if handled, err := d.assignBigFloat(data, out); handled {
+		return err
+	}
+	if handled, err := d.assignIP(data, out); handled {
+		return err
+	}
+	if handled, err := d.assignURL(data, out); handled {
+		return err
+	}
+
 	out = dereferencePtr(out)
 	switch data.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
@@ -233,32 +754,109 @@ func i2sReflect(data reflect.Value, out reflect.Value) error {
 		reflect.Bool,
 		reflect.String:
 		// assign simple types.
-		err := assignSimpleValue(out, data)
+		err := d.assignSimpleValue(out, data)
 		if err != nil {
-			return fmt.Errorf("assigning %s failed: %w", data.Type().Name(), err)
+			return fmt.Errorf("assigning %s failed: %w", data.Type(), err)
 		}
 		return nil
 	case reflect.Map:
-		return assignMap(data, out)
+		d.zeroBeforeDecode(out)
+		if isMapDestination(out) {
+			return d.assignMapToMap(data, out)
+		}
+		return d.assignMap(data, out)
 	case reflect.Array, reflect.Slice:
-		return assignArraySliceValue(out, data)
-	case reflect.Interface:
-		// unwrap interface and retry.
-		if data.IsNil() {
-			return nil
+		d.zeroBeforeDecode(out)
+		if d.positionalStruct(out) {
+			return d.assignPositional(data, out)
+		}
+		return d.assignArraySliceValue(out, data)
+	case reflect.Struct:
+		if handled, err := d.assignTextValue(data, out); handled {
+			return err
 		}
+		return d.assignStruct(data, out)
+	case reflect.Interface, reflect.Pointer:
+		// unwrap interface or pointer and retry.
 		data = dereferencePtr(data)
-		return i2sReflect(data, out)
-	case reflect.Invalid:
-		return nil
+		return d.i2sReflect(data, out)
 	default:
 		return fmt.Errorf("unsupported kind: %s", data.Kind())
 	}
 }
 
-// i2s is the top-level function that converts a generic data structure (like a map or slice)
-// into a strongly typed struct. `out` must be a pointer to the struct.
-func i2s(data interface{}, out interface{}) error {
+// interfaceDestination follows out through pointers and interfaces holding pointers, and
+// returns the settable interface it ends at, if that interface is nil or holds a non-pointer.
+func interfaceDestination(out reflect.Value) (reflect.Value, bool) {
+	for {
+		switch out.Kind() {
+		case reflect.Pointer:
+			if out.IsNil() {
+				return out, false
+			}
+			out = out.Elem()
+		case reflect.Interface:
+			if out.IsNil() || out.Elem().Kind() != reflect.Pointer {
+				return out, out.CanSet()
+			}
+			out = out.Elem()
+		default:
+			return out, false
+		}
+	}
+}
+
+// assignInterface stores the source value itself in an interface destination, deep-copying
+// maps and slices first when WithDeepCopyInterfaces is set.
+func (d *Decoder) assignInterface(data reflect.Value, out reflect.Value) error {
+	value := dereferencePtr(data).Interface()
+	if d.opts.DeepCopyInterfaces {
+		var err error
+		if value, err = d.deepCopyValue(value); err != nil {
+			return err
+		}
+	}
+
+	src := reflect.ValueOf(value)
+	if !src.Type().AssignableTo(out.Type()) {
+		return fmt.Errorf("cannot assign value of type %s to field of type %s", src.Type(), out.Type())
+	}
+	out.Set(src)
+	return nil
+}
+
+// zeroBeforeDecode resets out to its zero value when WithZeroBeforeDecode is set, so that the
+// result depends only on the source data.
+func (d *Decoder) zeroBeforeDecode(out reflect.Value) {
+	if d.opts.ZeroBeforeDecode && !d.merging() && out.CanSet() {
+		out.Set(reflect.Zero(out.Type()))
+	}
+}
+
+// assignNil handles a nil source value according to the configured NilSourceBehavior. While
+// patching, a nil value always resets out to its zero value.
+func (d *Decoder) assignNil(out reflect.Value) error {
+	if d.patching {
+		if out.CanSet() {
+			out.Set(reflect.Zero(out.Type()))
+		}
+		return nil
+	}
+	switch d.opts.NilSource {
+	case NilSourceZero:
+		if out.CanSet() {
+			out.Set(reflect.Zero(out.Type()))
+		}
+		return nil
+	case NilSourceError:
+		return ErrNilSource
+	default:
+		return nil
+	}
+}
+
+// decode is the entry point shared by i2s and Decode. `out` must be a pointer.
+func (d *Decoder) decode(data interface{}, out interface{}) error {
 	if data == nil {
 		return errors.New("data cannot be nil")
 	}
@@ -270,19 +868,157 @@ func i2s(data interface{}, out interface{}) error {
 		return fmt.Errorf("out must be a pointer, got %s", reflect.TypeOf(out).Kind())
 	}
 
-	return i2sReflect(dataVal, outVal)
+	if dataVal.Kind() == reflect.Map && dataVal.Type().Key().Kind() == reflect.String &&
+		(len(d.opts.Pipeline) > 0 || d.opts.ExpandDotKeys) {
+		m, err := d.reshape(copyStringMap(dataVal))
+		if err != nil {
+			return err
+		}
+		dataVal = reflect.ValueOf(m)
+	}
+
+	if err := d.i2sReflect(dataVal, outVal); err != nil {
+		return err
+	}
+
+	if d.opts.Fallback != nil && dereferencePtr(outVal).Kind() == reflect.Struct {
+		return d.applyFallback(dereferencePtr(outVal))
+	}
+	return nil
+}
+
+// reshape applies the transformations configured for the top-level source map: the pipeline
+// set with WithPipeline, then dot-key expansion.
+func (d *Decoder) reshape(m map[string]interface{}) (map[string]interface{}, error) {
+	m, err := applyPipeline(d.opts.Pipeline, m)
+	if err != nil {
+		return nil, err
+	}
+	if d.opts.ExpandDotKeys {
+		return expandDotKeys(m)
+	}
+	return m, nil
+}
+
+// applyFallback assigns the configured fallback values to the fields of out that are
+// still at their zero value after the primary source has been decoded.
+func (d *Decoder) applyFallback(out reflect.Value) error {
+	fieldsMap, err := d.mapStructFieldsByName(out)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range d.opts.Fallback {
+		field, ok := d.lookupField(fieldsMap, key)
+		if !ok || !d.decodable(field) || (field.value.IsValid() && !field.value.IsZero()) {
+			continue
+		}
+		if err := d.decodeField(key, reflect.ValueOf(value), field); err != nil {
+			return fmt.Errorf("fallback %q: %w", key, fieldError(field, err))
+		}
+	}
+	return nil
+}
+
+// i2s converts a generic data structure (like a map or slice) into a strongly typed value
+// using the default options. `out` must be a pointer to the destination. Maps with string keys
+// are decoded by MapToStruct.
+func i2s(data interface{}, out interface{}) error {
+	if m, ok := data.(map[string]interface{}); ok {
+		return MapToStruct(m, out)
+	}
+	return NewDecoder().decode(data, out)
+}
+
+// MapToStruct decodes data into the struct pointed to by out using the default options.
+// Decoders registered with RegisterFastDecoder take precedence. Use a Decoder for other
+// options.
+func MapToStruct(data map[string]interface{}, out interface{}) error {
+	d := NewDecoder()
+	if d.fastPathEnabled() {
+		if fn, ok := fastDecoderFor(out); ok {
+			return fn(data, out)
+		}
+	}
+	return d.decode(data, out)
+}
+
+// SliceToStructSlice decodes data, typically a []interface{} of maps, into the slice of
+// structs, or of pointers to structs, pointed to by out using the default options. Each
+// element of data becomes one element of the slice, which is replaced.
+func SliceToStructSlice(data []interface{}, out interface{}) error {
+	t := reflect.TypeOf(out)
+	if t == nil || t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Slice ||
+		indirectType(t.Elem().Elem()).Kind() != reflect.Struct {
+		return fmt.Errorf("out must be a pointer to a slice of structs, got %T", out)
+	}
+	return NewDecoder().decode(data, out)
 }
 
 // Decoder is a struct used to perform decoding of generic data into typed structs.
-type Decoder struct{}
+// A Decoder is safe for concurrent use: every call runs on a session holding its own per-call
+// state, see session. Options are fixed by NewDecoder, and type hooks may be registered at
+// any time. A Decoder must not be copied.
+type Decoder struct {
+	opts      DecoderOptions
+	typeHooks map[reflect.Type]TypeHookFunc
+	metas     *sync.Map
+
+	// mu guards typeHooks, sources and timings of a Decoder shared between sessions.
+	mu       sync.RWMutex
+	sessions sync.Pool
+
+	// results accumulated until Reset; sessions record their own and merge them on release.
+	sources map[string]SourceInfo
+	timings map[string]time.Duration
+
+	// per-call state of a session.
+	sourceIndex int
+	path        []string
+	// ancestors holds the maps and pointers being decoded, see descend.
+	ancestors []uintptr
+	// patching is set while Patch runs.
+	patching bool
+	// selected holds the fields chosen by DecodeFields or DecodeExcept, see isSelected.
+	selected map[fieldKey]bool
+	// ctx bounds a call to DecodeContext.
+	ctx context.Context
+}
 
-// NewDecoder creates a new instance of Decoder.
-func NewDecoder() *Decoder {
-	return &Decoder{}
+// NewDecoder creates a new instance of Decoder configured by the given options.
+func NewDecoder(opts ...Option) *Decoder {
+	d := &Decoder{}
+	for _, opt := range opts {
+		opt(&d.opts)
+	}
+	d.metas = metaCacheFor(d.opts.tagKey())
+	return d
 }
 
 // Decode decodes the provided generic data into the given output struct pointer.
 // It returns an error if the decoding fails.
 func (d *Decoder) Decode(data interface{}, out interface{}) error {
-	return i2s(data, out)
+	return d.wrapError(out, d.decodeSession(data, out))
+}
+
+// decodeSession is Decode without WithWrapErrors, for callers that wrap errors themselves.
+func (d *Decoder) decodeSession(data interface{}, out interface{}) error {
+	s := d.session()
+	defer d.release(s)
+	return s.decode(data, out)
+}
+
+// MergeInto decodes each source into out in order, so that keys present in later sources
+// override values set by earlier ones. Fields absent from every source are left unchanged.
+func (d *Decoder) MergeInto(out interface{}, sources ...interface{}) error {
+	s := d.session()
+	defer d.release(s)
+
+	for i, src := range sources {
+		s.sourceIndex = i
+		if err := s.decode(src, out); err != nil {
+			return d.wrapError(out, fmt.Errorf("source %d: %w", i, err))
+		}
+	}
+	return nil
 }
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math"
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 type Simple struct {
@@ -34,7 +40,7 @@ func TestMapStructFieldsByName(t *testing.T) {
 	t.Run("valid struct", func(t *testing.T) {
 		s := Simple{}
 		v := reflect.ValueOf(&s)
-		fields, err := mapStructFieldsByName(v)
+		fields, err := NewDecoder().mapStructFieldsByName(v)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -45,7 +51,7 @@ func TestMapStructFieldsByName(t *testing.T) {
 
 	t.Run("non-struct", func(t *testing.T) {
 		var i int
-		_, err := mapStructFieldsByName(reflect.ValueOf(&i))
+		_, err := NewDecoder().mapStructFieldsByName(reflect.ValueOf(&i))
 		if err == nil {
 			t.Error("expected error for non-struct type")
 		}
@@ -53,7 +59,7 @@ func TestMapStructFieldsByName(t *testing.T) {
 
 	t.Run("nil pointer", func(t *testing.T) {
 		var s *Simple
-		_, err := mapStructFieldsByName(reflect.ValueOf(s))
+		_, err := NewDecoder().mapStructFieldsByName(reflect.ValueOf(s))
 		if err == nil {
 			t.Error("expected error for nil pointer")
 		}
@@ -104,7 +110,7 @@ func TestAssignSimpleValue(t *testing.T) {
 			dst := reflect.New(reflect.TypeOf(tt.dst)).Elem()
 			src := reflect.ValueOf(tt.src)
 
-			err := assignSimpleValue(dst, src)
+			err := NewDecoder().assignSimpleValue(dst, src)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("assignSimpleValue() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -122,7 +128,7 @@ func TestAssignArraySliceValue(t *testing.T) {
 		src := []interface{}{1, 2, 3}
 		var dst []int
 
-		err := assignArraySliceValue(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(src))
+		err := NewDecoder().assignArraySliceValue(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(src))
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -133,7 +139,7 @@ func TestAssignArraySliceValue(t *testing.T) {
 
 	t.Run("invalid src type", func(t *testing.T) {
 		var dst []int
-		err := assignArraySliceValue(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(42))
+		err := NewDecoder().assignArraySliceValue(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(42))
 		if err == nil {
 			t.Error("expected error for non-slice src")
 		}
@@ -142,7 +148,7 @@ func TestAssignArraySliceValue(t *testing.T) {
 	t.Run("invalid dst type", func(t *testing.T) {
 		src := []interface{}{1, 2, 3}
 		var dst int
-		err := assignArraySliceValue(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(src))
+		err := NewDecoder().assignArraySliceValue(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(src))
 		if err == nil {
 			t.Error("expected error for non-slice dst")
 		}
@@ -152,7 +158,7 @@ func TestAssignArraySliceValue(t *testing.T) {
 		src := [][]interface{}{{"a", "b"}, {"c"}}
 		var dst [][]string
 
-		err := assignArraySliceValue(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(src))
+		err := NewDecoder().assignArraySliceValue(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(src))
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -160,6 +166,79 @@ func TestAssignArraySliceValue(t *testing.T) {
 			t.Errorf("unexpected result: %v", dst)
 		}
 	})
+
+	t.Run("array destination", func(t *testing.T) {
+		dst := [4]int{9, 9, 9, 9}
+		err := NewDecoder().assignArraySliceValue(reflect.ValueOf(&dst).Elem(), reflect.ValueOf([]interface{}{1, 2.0}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst != [4]int{1, 2, 0, 0} {
+			t.Errorf("unexpected result: %v", dst)
+		}
+	})
+
+	t.Run("array struct field", func(t *testing.T) {
+		type Matrix struct {
+			Rows [2][2]float64
+			Key  [3]byte
+		}
+		var dst Matrix
+		src := map[string]interface{}{
+			"Rows": []interface{}{[]interface{}{1, 2}, []interface{}{3, 4}},
+			"Key":  []byte{1, 2, 3},
+		}
+		if err := NewDecoder().Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Rows != [2][2]float64{{1, 2}, {3, 4}} || dst.Key != [3]byte{1, 2, 3} {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("pointer to slice fields", func(t *testing.T) {
+		type Lists struct {
+			IDs    *[]int
+			Names  **[]string
+			Raw    *[]byte
+			Fixed  *[2]int
+			Absent *[]int
+		}
+		existing := []string{"old"}
+		dst := Lists{Names: func() **[]string { p := &existing; return &p }()}
+		src := map[string]interface{}{
+			"IDs":   []interface{}{1, 2},
+			"Names": []interface{}{"x"},
+			"Raw":   "hi",
+			"Fixed": []interface{}{3},
+		}
+		if err := NewDecoder().Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.IDs == nil || !slices.Equal(*dst.IDs, []int{1, 2}) {
+			t.Errorf("unexpected IDs: %v", dst.IDs)
+		}
+		if !slices.Equal(existing, []string{"x"}) {
+			t.Errorf("expected existing slice to be replaced through the pointer, got %v", existing)
+		}
+		if dst.Raw == nil || string(*dst.Raw) != "hi" || dst.Fixed == nil || *dst.Fixed != [2]int{3, 0} {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+		if dst.Absent != nil {
+			t.Errorf("expected absent field to stay nil, got %v", dst.Absent)
+		}
+	})
+
+	t.Run("array too short", func(t *testing.T) {
+		dst := [2]int{7, 8}
+		err := NewDecoder().assignArraySliceValue(reflect.ValueOf(&dst).Elem(), reflect.ValueOf([]int{1, 2, 3}))
+		if err == nil {
+			t.Error("expected error for source longer than array")
+		}
+		if dst != [2]int{7, 8} {
+			t.Errorf("array modified on error: %v", dst)
+		}
+	})
 }
 
 func TestAssignMap(t *testing.T) {
@@ -174,7 +253,7 @@ func TestAssignMap(t *testing.T) {
 		}
 		var dst Simple
 
-		err := assignMap(reflect.ValueOf(src), reflect.ValueOf(&dst).Elem())
+		err := NewDecoder().assignMap(reflect.ValueOf(src), reflect.ValueOf(&dst).Elem())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -186,7 +265,7 @@ func TestAssignMap(t *testing.T) {
 	t.Run("invalid map key type", func(t *testing.T) {
 		src := map[int]interface{}{1: "test"}
 		var dst Simple
-		err := assignMap(reflect.ValueOf(src), reflect.ValueOf(&dst).Elem())
+		err := NewDecoder().assignMap(reflect.ValueOf(src), reflect.ValueOf(&dst).Elem())
 		if err == nil {
 			t.Error("expected error for non-string map key")
 		}
@@ -195,11 +274,21 @@ func TestAssignMap(t *testing.T) {
 	t.Run("nil map", func(t *testing.T) {
 		var src map[string]interface{}
 		var dst Simple
-		err := assignMap(reflect.ValueOf(src), reflect.ValueOf(&dst).Elem())
+		err := NewDecoder().assignMap(reflect.ValueOf(src), reflect.ValueOf(&dst).Elem())
 		if err != nil {
 			t.Errorf("unexpected error for nil map: %v", err)
 		}
 	})
+
+	t.Run("nil typed pointer destination", func(t *testing.T) {
+		src := map[string]interface{}{"KeyInt": 1}
+		if err := NewDecoder().Decode(src, (*Simple)(nil)); err == nil {
+			t.Error("expected error for nil pointer destination")
+		}
+		if err := i2s(src, (*Simple)(nil)); err == nil {
+			t.Error("expected error for nil pointer destination")
+		}
+	})
 }
 
 func TestI2S(t *testing.T) {
@@ -286,6 +375,35 @@ func TestI2S(t *testing.T) {
 	})
 }
 
+func TestMapToStruct(t *testing.T) {
+	var dst Simple
+	if err := MapToStruct(map[string]interface{}{"KeyInt": 42, "KeyString": "test"}, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.KeyInt != 42 || dst.KeyString != "test" {
+		t.Errorf("unexpected result: %+v", dst)
+	}
+
+	t.Run("slice of structs", func(t *testing.T) {
+		src := []interface{}{map[string]interface{}{"KeyInt": 1}, map[string]interface{}{"KeyInt": 2}}
+		var dst []*Simple
+		if err := SliceToStructSlice(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(dst) != 2 || dst[0].KeyInt != 1 || dst[1].KeyInt != 2 {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("slice of non-structs", func(t *testing.T) {
+		var dst []int
+		err := SliceToStructSlice([]interface{}{1}, &dst)
+		if err == nil || !strings.Contains(err.Error(), "slice of structs") {
+			t.Errorf("expected error, got %v", err)
+		}
+	})
+}
+
 func TestDereferencePtr(t *testing.T) {
 	t.Run("non-pointer", func(t *testing.T) {
 		v := reflect.ValueOf(42)
@@ -308,7 +426,7 @@ func TestI2SReflect(t *testing.T) {
 	t.Run("interface value", func(t *testing.T) {
 		var src interface{} = map[string]interface{}{"KeyInt": 42}
 		var dst Simple
-		err := i2sReflect(reflect.ValueOf(src), reflect.ValueOf(&dst).Elem())
+		err := NewDecoder().i2sReflect(reflect.ValueOf(src), reflect.ValueOf(&dst).Elem())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -318,10 +436,9 @@ func TestI2SReflect(t *testing.T) {
 	})
 
 	t.Run("unsupported kind", func(t *testing.T) {
-		type unsupported struct{ f func() }
-		src := unsupported{}
-		var dst unsupported
-		err := i2sReflect(reflect.ValueOf(src), reflect.ValueOf(&dst).Elem())
+		src := func() {}
+		var dst Simple
+		err := NewDecoder().i2sReflect(reflect.ValueOf(src), reflect.ValueOf(&dst).Elem())
 		if err == nil {
 			t.Error("expected error for unsupported kind")
 		}
@@ -524,3 +641,669 @@ func TestPointerHandling(t *testing.T) {
 		}
 	})
 }
+
+func TestFieldCollision(t *testing.T) {
+	t.Run("tag renames field", func(t *testing.T) {
+		type Renamed struct {
+			UserID int `gomap:"user_id"`
+		}
+		var dst Renamed
+		if err := i2s(map[string]interface{}{"user_id": 5}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.UserID != 5 {
+			t.Errorf("expected 5, got %d", dst.UserID)
+		}
+	})
+
+	t.Run("same depth collision", func(t *testing.T) {
+		type Colliding struct {
+			ID    int
+			Ident int `gomap:"ID"`
+		}
+		var dst Colliding
+		err := i2s(map[string]interface{}{"ID": 1}, &dst)
+
+		var collision *FieldCollisionError
+		if !errors.As(err, &collision) {
+			t.Fatalf("expected FieldCollisionError, got %v", err)
+		}
+		if collision.EffectiveName != "ID" || !reflect.DeepEqual(collision.Fields, []string{"ID", "Ident"}) {
+			t.Errorf("unexpected collision: %+v", collision)
+		}
+	})
+
+	t.Run("prefer outer", func(t *testing.T) {
+		outer := structField{name: "Outer", depth: 0}
+		inner := structField{name: "Inner", depth: 1}
+		field, err := NewDecoder().resolveCollision("Name", []structField{inner, outer})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if field.name != "Outer" {
+			t.Errorf("expected outer field to win, got %s", field.name)
+		}
+	})
+
+	t.Run("error strategy", func(t *testing.T) {
+		outer := structField{name: "Outer", depth: 0}
+		inner := structField{name: "Inner", depth: 1}
+		d := NewDecoder(WithCollisionStrategy(CollisionError))
+		_, err := d.resolveCollision("Name", []structField{outer, inner})
+
+		var collision *FieldCollisionError
+		if !errors.As(err, &collision) {
+			t.Fatalf("expected FieldCollisionError, got %v", err)
+		}
+	})
+}
+
+func TestEmbeddedStructs(t *testing.T) {
+	type Timestamps struct {
+		CreatedAt string
+		UpdatedAt string
+	}
+	type Base struct {
+		ID   int
+		Name string
+		Timestamps
+	}
+	type Audit struct {
+		Editor string
+	}
+
+	t.Run("embedded value", func(t *testing.T) {
+		type User struct {
+			Base
+			Email string
+		}
+		var dst User
+		err := i2s(map[string]interface{}{"ID": 1, "Name": "john", "Email": "j@x"}, &dst)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.ID != 1 || dst.Name != "john" || dst.Email != "j@x" {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("embedded pointer", func(t *testing.T) {
+		type User struct {
+			*Audit
+			Email string
+		}
+		var dst User
+		if err := i2s(map[string]interface{}{"Editor": "jane"}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Audit == nil || dst.Editor != "jane" {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("multi-level", func(t *testing.T) {
+		type User struct {
+			Base
+		}
+		var dst User
+		if err := i2s(genericMap{"CreatedAt": "today", "ID": 2}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.CreatedAt != "today" || dst.ID != 2 {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("outer field wins", func(t *testing.T) {
+		type User struct {
+			Base
+			Name string
+		}
+		var dst User
+		if err := i2s(map[string]interface{}{"Name": "john"}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Name != "john" || dst.Base.Name != "" {
+			t.Errorf("expected outer Name to be set, got %+v", dst)
+		}
+	})
+
+	t.Run("nil embedded pointer left alone", func(t *testing.T) {
+		type User struct {
+			*Audit
+			Name string
+		}
+		sources := map[string]interface{}{
+			"interface values": map[string]interface{}{"Name": "john"},
+			"string values":    map[string]string{"Name": "john"},
+			"named map":        genericMap{"Name": "john"},
+		}
+		for name, src := range sources {
+			for _, d := range []*Decoder{NewDecoder(), NewDecoder(WithLazyOptional())} {
+				var dst User
+				if err := d.Decode(src, &dst); err != nil {
+					t.Fatalf("%s: unexpected error: %v", name, err)
+				}
+				if dst.Name != "john" || dst.Audit != nil {
+					t.Errorf("%s: expected only Name to be set, got %+v", name, dst)
+				}
+			}
+		}
+
+		var dst User
+		if err := i2s(map[string]string{"Editor": "jane"}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Audit == nil || dst.Editor != "jane" {
+			t.Errorf("expected embedded pointer to be allocated for Editor, got %+v", dst)
+		}
+	})
+
+	t.Run("tagged embedded struct", func(t *testing.T) {
+		type User struct {
+			Audit `gomap:"audit"`
+			Name  string
+		}
+		var dst User
+		src := map[string]interface{}{"audit": map[string]interface{}{"Editor": "jane"}, "Editor": "x"}
+		if err := i2s(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Editor != "jane" {
+			t.Errorf("expected tagged embedded struct to decode as a nested field, got %+v", dst)
+		}
+	})
+}
+
+func TestNamedTypeAliases(t *testing.T) {
+	type Celsius float64
+	type UserID int64
+	type Status string
+	type Reading struct {
+		Temp   Celsius
+		Owner  UserID
+		Status Status
+	}
+
+	t.Run("matching kinds", func(t *testing.T) {
+		src := map[string]interface{}{"Temp": 21.5, "Owner": int64(7), "Status": "ok"}
+		var dst Reading
+		if err := i2s(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Temp != 21.5 || dst.Owner != 7 || dst.Status != "ok" {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("compatible kinds", func(t *testing.T) {
+		src := map[string]interface{}{"Temp": 21, "Owner": uint8(7), "Status": Status("ok")}
+		var dst Reading
+		if err := i2s(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Temp != 21 || dst.Owner != 7 || dst.Status != "ok" {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("alias source into plain field", func(t *testing.T) {
+		src := map[string]interface{}{"KeyFloat": Celsius(1.5), "KeyString": Status("x")}
+		var dst Simple
+		if err := i2s(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.KeyFloat != 1.5 || dst.KeyString != "x" {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("error names alias type", func(t *testing.T) {
+		var dst Reading
+		err := i2s(map[string]interface{}{"Status": 1}, &dst)
+		if err == nil || !strings.Contains(err.Error(), "Status") {
+			t.Errorf("expected error naming Status type, got %v", err)
+		}
+		err = i2s(map[string]interface{}{"Temp": "hot"}, &dst)
+		if err == nil || !strings.Contains(err.Error(), "Celsius") {
+			t.Errorf("expected error naming Celsius type, got %v", err)
+		}
+	})
+
+	t.Run("coercion applies to alias", func(t *testing.T) {
+		r := NewCoercionRegistry()
+		r.Register(reflect.String, reflect.Int64, func(v reflect.Value) (reflect.Value, error) {
+			return reflect.ValueOf(int64(len(v.String()))), nil
+		})
+		var dst Reading
+		if err := NewDecoder(WithCoercionRegistry(r)).Decode(map[string]interface{}{"Owner": "abc"}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Owner != 3 {
+			t.Errorf("expected coerced value 3, got %d", dst.Owner)
+		}
+	})
+}
+
+func TestFastPath(t *testing.T) {
+	type Mixed struct {
+		S   string
+		I   int
+		I64 int64
+		F   float64
+		B   bool
+		U   uint
+		P   *int
+	}
+
+	src := map[string]interface{}{
+		"S": "s", "I": 1, "I64": 2, "F": 1.5, "B": true, "U": 3, "P": 4,
+	}
+
+	var fast, slow Mixed
+	if err := i2s(src, &fast); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := i2s(genericMap(src), &slow); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fast.P == nil || *fast.P != 4 || *slow.P != 4 {
+		t.Fatalf("unexpected pointer field: %v %v", fast.P, slow.P)
+	}
+	fast.P, slow.P = nil, nil
+	if fast != slow {
+		t.Errorf("fast path %+v differs from reflection path %+v", fast, slow)
+	}
+
+	t.Run("nil values keep fields", func(t *testing.T) {
+		dst := Mixed{S: "keep"}
+		if err := i2s(map[string]interface{}{"S": nil}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.S != "keep" {
+			t.Errorf("expected field to be kept, got %q", dst.S)
+		}
+	})
+
+	t.Run("type mismatch falls back", func(t *testing.T) {
+		var dst Mixed
+		if err := i2s(map[string]interface{}{"B": "yes"}, &dst); err == nil {
+			t.Error("expected type mismatch error")
+		}
+	})
+}
+
+func TestJSONNumberSource(t *testing.T) {
+	type Payment struct {
+		ID     int64
+		Amount float64
+		Count  uint8
+		Ref    string
+		Small  int8
+	}
+
+	dec := json.NewDecoder(strings.NewReader(`{"ID": 9007199254740993, "Amount": 12.5, "Count": 3, "Ref": 42}`))
+	dec.UseNumber()
+	var src map[string]interface{}
+	if err := dec.Decode(&src); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var dst Payment
+	if err := i2s(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Payment{ID: 9007199254740993, Amount: 12.5, Count: 3, Ref: "42"}
+	if dst != want {
+		t.Errorf("expected %+v, got %+v", want, dst)
+	}
+
+	for _, bad := range []map[string]interface{}{
+		{"ID": json.Number("1.5")},
+		{"Small": json.Number("300")},
+		{"Count": json.Number("-1")},
+	} {
+		if err := i2s(bad, &dst); err == nil {
+			t.Errorf("%v: expected error", bad)
+		}
+	}
+}
+
+func TestUnexportedFields(t *testing.T) {
+	type Account struct {
+		Name    string
+		balance int
+		secret  string
+		Tags    []string
+	}
+
+	src := map[string]interface{}{"Name": "alice", "balance": 100, "secret": "x", "Tags": []interface{}{"a"}}
+	for _, d := range []*Decoder{NewDecoder(), NewDecoder(WithZeroMissing()), NewDecoder(WithWeakDecode())} {
+		var dst Account
+		if err := d.Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Name != "alice" || dst.balance != 0 || dst.secret != "" || len(dst.Tags) != 1 {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	}
+
+	t.Run("strict mode reports unexported keys as unknown", func(t *testing.T) {
+		var dst Account
+		err := NewDecoder(WithStrictMode()).Decode(src, &dst)
+		var unknown *UnknownKeysError
+		if !errors.As(err, &unknown) || !slices.Equal(unknown.Keys, []string{"balance", "secret"}) {
+			t.Fatalf("expected unknown balance and secret, got %v", err)
+		}
+	})
+
+	t.Run("unaddressable struct", func(t *testing.T) {
+		d := NewDecoder()
+		for _, data := range []interface{}{src, map[string]int{"Name": 1}} {
+			err := d.assignMap(reflect.ValueOf(data), reflect.ValueOf(Account{}))
+			if err == nil || !strings.Contains(err.Error(), "cannot be set") {
+				t.Errorf("expected unsettable field error, got %v", err)
+			}
+		}
+	})
+}
+
+func TestTypedMapSources(t *testing.T) {
+	type Level int
+	type Inner struct {
+		A int
+		B string
+	}
+	type Target struct {
+		Count  int
+		Big    int64
+		Ptr    *int
+		Ratio  float64
+		Size   uint
+		Any    interface{}
+		Name   string
+		Level  Level
+		Nested Inner
+		Opt    *Inner
+		List   []int
+		Counts map[string]int
+	}
+
+	n := 7
+	tests := []struct {
+		name  string
+		src   interface{}
+		check func(Target) bool
+	}{
+		{"map[string]int", map[string]int{"Count": 1, "Big": 2, "Ptr": 3, "Ratio": 4, "Size": 5, "Any": 6, "Level": 7},
+			func(dst Target) bool {
+				return dst.Count == 1 && dst.Big == 2 && dst.Ptr != nil && *dst.Ptr == 3 && dst.Ratio == 4 &&
+					dst.Size == 5 && dst.Any == 6 && dst.Level == 7
+			}},
+		{"map[string]string", map[string]string{"Name": "x", "Any": "y"},
+			func(dst Target) bool { return dst.Name == "x" && dst.Any == "y" }},
+		{"map[string]float64", map[string]float64{"Ratio": 1.5, "Count": 2},
+			func(dst Target) bool { return dst.Ratio == 1.5 && dst.Count == 2 }},
+		{"map[string]Level", map[string]Level{"Level": 3, "Count": 4},
+			func(dst Target) bool { return dst.Level == 3 && dst.Count == 4 }},
+		{"map[string]*int", map[string]*int{"Count": &n, "Ptr": &n},
+			func(dst Target) bool { return dst.Count == 7 && dst.Ptr != nil && *dst.Ptr == 7 && dst.Ptr != &n }},
+		{"map[string][]int", map[string][]int{"List": {1, 2}},
+			func(dst Target) bool { return slices.Equal(dst.List, []int{1, 2}) }},
+		{"map[string]map[string]int", map[string]map[string]int{"Nested": {"A": 1}, "Opt": {"A": 2}, "Counts": {"k": 3}},
+			func(dst Target) bool {
+				return dst.Nested.A == 1 && dst.Opt != nil && dst.Opt.A == 2 && dst.Counts["k"] == 3
+			}},
+		{"map[string]Inner", map[string]Inner{"Nested": {A: 1, B: "b"}, "Opt": {A: 2}},
+			func(dst Target) bool { return dst.Nested == Inner{A: 1, B: "b"} && dst.Opt != nil && dst.Opt.A == 2 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst Target
+			if err := i2s(tt.src, &dst); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.check(dst) {
+				t.Errorf("unexpected result: %+v", dst)
+			}
+		})
+	}
+
+	t.Run("mismatched value type", func(t *testing.T) {
+		var dst Target
+		if err := i2s(map[string]string{"Count": "1"}, &dst); err == nil {
+			t.Error("expected error assigning string to int field")
+		}
+	})
+}
+
+func TestMultiLevelPointers(t *testing.T) {
+	type Inner struct {
+		A int
+	}
+	type Chains struct {
+		Int     **int
+		Str     ***string
+		Struct  **Inner
+		Struct3 ***Inner
+		List    []**int
+		Map     map[string]**int
+	}
+
+	t.Run("nil chains are allocated", func(t *testing.T) {
+		src := map[string]interface{}{
+			"Int":     1,
+			"Str":     "x",
+			"Struct":  map[string]interface{}{"A": 2},
+			"Struct3": Inner{A: 3},
+			"List":    []interface{}{4},
+			"Map":     map[string]interface{}{"k": 5},
+		}
+		var dst Chains
+		if err := i2s(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if **dst.Int != 1 || ***dst.Str != "x" || (*dst.Struct).A != 2 || (**dst.Struct3).A != 3 ||
+			**dst.List[0] != 4 || **dst.Map["k"] != 5 {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("existing chains are reused", func(t *testing.T) {
+		n := 9
+		p := &n
+		inner := &Inner{A: 1}
+		dst := Chains{Int: &p, Struct: &inner}
+		if err := i2s(map[string]interface{}{"Int": 10, "Struct": map[string]interface{}{"A": 11}}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != 10 || inner.A != 11 {
+			t.Errorf("expected values to be written through existing pointers, got %d and %d", n, inner.A)
+		}
+	})
+
+	t.Run("nil source leaves chains nil", func(t *testing.T) {
+		var dst Chains
+		if err := i2s(map[string]interface{}{"Int": nil, "Struct": nil}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Int != nil || dst.Struct != nil {
+			t.Errorf("expected nil pointers, got %+v", dst)
+		}
+	})
+
+	t.Run("pointer chain source", func(t *testing.T) {
+		n := 3
+		p := &n
+		var dst Chains
+		if err := i2s(map[string]interface{}{"Int": &p}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if **dst.Int != 3 || *dst.Int == p {
+			t.Errorf("expected a copy of the source chain, got %v", dst.Int)
+		}
+	})
+}
+
+func TestInterfaceFields(t *testing.T) {
+	type Inner struct {
+		A int
+	}
+	type Envelope struct {
+		Value    interface{}
+		Stringer fmt.Stringer
+	}
+
+	tests := []struct {
+		name string
+		src  interface{}
+	}{
+		{"int", 42},
+		{"string", "hello"},
+		{"float", 1.5},
+		{"bool", true},
+		{"map", map[string]interface{}{"a": 1, "b": []interface{}{"x"}}},
+		{"slice", []interface{}{1, "two", 3.0}},
+		{"typed slice", []int{1, 2}},
+		{"struct", Inner{A: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst Envelope
+			if err := i2s(map[string]interface{}{"Value": tt.src}, &dst); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(dst.Value, tt.src) {
+				t.Errorf("Value = %#v, want %#v", dst.Value, tt.src)
+			}
+		})
+	}
+
+	t.Run("pointer sources are dereferenced", func(t *testing.T) {
+		n := 7
+		var dst Envelope
+		if err := i2s(map[string]interface{}{"Value": &n}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Value != 7 {
+			t.Errorf("Value = %#v, want 7", dst.Value)
+		}
+	})
+
+	t.Run("nil source", func(t *testing.T) {
+		dst := Envelope{Value: "old"}
+		if err := i2s(map[string]interface{}{"Value": nil}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Value != "old" {
+			t.Errorf("expected nil to be skipped by default, got %#v", dst.Value)
+		}
+	})
+
+	t.Run("existing pointer is decoded into", func(t *testing.T) {
+		inner := &Inner{}
+		dst := Envelope{Value: inner}
+		if err := i2s(map[string]interface{}{"Value": map[string]interface{}{"A": 3}}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Value != inner || inner.A != 3 {
+			t.Errorf("expected decoding through the stored pointer, got %#v", dst.Value)
+		}
+	})
+
+	t.Run("non-empty interface", func(t *testing.T) {
+		var dst Envelope
+		if err := i2s(map[string]interface{}{"Stringer": time.Second}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Stringer != time.Second {
+			t.Errorf("Stringer = %#v, want 1s", dst.Stringer)
+		}
+		if err := i2s(map[string]interface{}{"Stringer": 1}, &dst); err == nil {
+			t.Error("expected error for a value not implementing fmt.Stringer")
+		}
+	})
+}
+
+func TestNamedTypes(t *testing.T) {
+	type Celsius float64
+	type Fahrenheit float64
+	type UserID int64
+	type LegacyID int32
+	type Code string
+	type Label string
+	type Reading struct {
+		Temp    Celsius
+		TempPtr *Celsius
+		User    UserID
+		Code    Code
+		Codes   []Code
+		ByCode  map[Code]UserID
+	}
+
+	tests := []struct {
+		name string
+		src  map[string]interface{}
+		want Reading
+	}{
+		{
+			"plain sources",
+			map[string]interface{}{"Temp": 21.5, "User": 42, "Code": "ok"},
+			Reading{Temp: 21.5, User: 42, Code: "ok"},
+		},
+		{
+			"differently named sources",
+			map[string]interface{}{"Temp": Fahrenheit(70), "User": LegacyID(7), "Code": Label("x")},
+			Reading{Temp: 70, User: 7, Code: "x"},
+		},
+		{
+			"cross-kind numeric sources",
+			map[string]interface{}{"Temp": UserID(3), "User": Celsius(8)},
+			Reading{Temp: 3, User: 8},
+		},
+		{
+			"named elements and keys",
+			map[string]interface{}{"Codes": []Label{"a", "b"}, "ByCode": map[Label]LegacyID{"k": 1}},
+			Reading{Codes: []Code{"a", "b"}, ByCode: map[Code]UserID{"k": 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst Reading
+			if err := i2s(tt.src, &dst); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(dst, tt.want) {
+				t.Errorf("got %+v, want %+v", dst, tt.want)
+			}
+		})
+	}
+
+	t.Run("pointer to named type", func(t *testing.T) {
+		var dst Reading
+		if err := i2s(map[string]interface{}{"TempPtr": Fahrenheit(-4)}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.TempPtr == nil || *dst.TempPtr != -4 {
+			t.Errorf("unexpected TempPtr: %v", dst.TempPtr)
+		}
+	})
+
+	t.Run("strict types compare kinds", func(t *testing.T) {
+		var dst Reading
+		d := NewDecoder(WithStrictTypes())
+		if err := d.Decode(map[string]interface{}{"Temp": Fahrenheit(1), "Code": Label("y")}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := d.Decode(map[string]interface{}{"User": LegacyID(1)}, &dst); err == nil {
+			t.Error("expected int32 source to be rejected for an int64 field")
+		}
+	})
+
+	t.Run("numbers are not converted to strings", func(t *testing.T) {
+		var dst Reading
+		if err := i2s(map[string]interface{}{"Code": UserID(65)}, &dst); err == nil {
+			t.Errorf("expected error, got Code %q", dst.Code)
+		}
+	})
+}
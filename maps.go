@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// isMapDestination reports whether out is a map or a pointer to one.
+func isMapDestination(out reflect.Value) bool {
+	return indirectType(out.Type()).Kind() == reflect.Map
+}
+
+// stringKeyedMap converts the map data, whose keys are interface{} values as in the output of
+// YAML decoders, into a map[string]interface{}, formatting each key with %v.
+func stringKeyedMap(data reflect.Value) reflect.Value {
+	m := make(map[string]interface{}, data.Len())
+	iter := data.MapRange()
+	for iter.Next() {
+		m[fmt.Sprintf("%v", iter.Key().Interface())] = iter.Value().Interface()
+	}
+	return reflect.ValueOf(m)
+}
+
+// assignMapToMap copies the map data into the map destination out element by element,
+// converting keys and values to the destination's types with i2sReflect. Values stored in
+// interface{} elements are kept as they are. A nil destination map is created first. With
+// WithAllowInterfaceMapKeys, interface{} keys are formatted into string-keyed destinations.
+func (d *Decoder) assignMapToMap(data reflect.Value, out reflect.Value) error {
+	if data.IsNil() {
+		return nil
+	}
+
+	for out.Kind() == reflect.Pointer {
+		if out.IsNil() {
+			if err := d.allocatePtr(out); err != nil {
+				return err
+			}
+		}
+		out = out.Elem()
+	}
+	if out.IsNil() {
+		out.Set(reflect.MakeMapWithSize(out.Type(), data.Len()))
+	}
+
+	keyType, elemType := out.Type().Key(), out.Type().Elem()
+	if d.opts.AllowInterfaceMapKeys && data.Type().Key().Kind() == reflect.Interface && keyType.Kind() == reflect.String {
+		data = stringKeyedMap(data)
+	}
+	iter := data.MapRange()
+	for iter.Next() {
+		key := reflect.New(keyType).Elem()
+		if err := d.i2sReflect(iter.Key(), key); err != nil {
+			return fmt.Errorf("map key %v: %w", iter.Key(), err)
+		}
+		if d.patching && iter.Value().Kind() == reflect.Interface && iter.Value().IsNil() {
+			out.SetMapIndex(key, reflect.Value{})
+			continue
+		}
+		elem := reflect.New(elemType).Elem()
+		if err := d.i2sReflect(iter.Value(), elem); err != nil {
+			if prefixPath(err, fmt.Sprint(iter.Key())) {
+				return err
+			}
+			return fmt.Errorf("map key %v: %w", iter.Key(), err)
+		}
+		out.SetMapIndex(key, elem)
+	}
+	return nil
+}
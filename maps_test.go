@@ -0,0 +1,180 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMapDestinations(t *testing.T) {
+	src := map[string]interface{}{
+		"name":   "john",
+		"nested": map[string]interface{}{"a": 1},
+		"tags":   []interface{}{"x"},
+	}
+
+	t.Run("map[string]interface{} keeps values", func(t *testing.T) {
+		var dst map[string]interface{}
+		if err := NewDecoder().Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(dst, src) {
+			t.Errorf("expected %v, got %v", src, dst)
+		}
+	})
+
+	t.Run("typed values are converted", func(t *testing.T) {
+		var dst map[string]float64
+		if err := NewDecoder().Decode(map[string]interface{}{"a": 1, "b": uint8(2), "c": 2.5}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := map[string]float64{"a": 1, "b": 2, "c": 2.5}
+		if !reflect.DeepEqual(dst, want) {
+			t.Errorf("expected %v, got %v", want, dst)
+		}
+	})
+
+	t.Run("existing map is filled", func(t *testing.T) {
+		dst := map[string]int{"old": 1}
+		if err := NewDecoder().Decode(map[string]interface{}{"new": 2}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(dst, map[string]int{"old": 1, "new": 2}) {
+			t.Errorf("unexpected result: %v", dst)
+		}
+	})
+
+	t.Run("map fields of structs", func(t *testing.T) {
+		type Config struct {
+			Labels map[string]string
+			Limits *map[string]int
+		}
+		var dst Config
+		data := map[string]interface{}{
+			"Labels": map[string]interface{}{"env": "prod"},
+			"Limits": map[string]interface{}{"cpu": 2},
+		}
+		if err := NewDecoder().Decode(data, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Labels["env"] != "prod" || dst.Limits == nil || (*dst.Limits)["cpu"] != 2 {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("pointer elements", func(t *testing.T) {
+		type Server struct {
+			Host string
+			Port int
+		}
+		type Config struct {
+			Servers map[string]*Server
+			Weights map[string]*int
+		}
+		var dst Config
+		data := map[string]interface{}{
+			"Servers": map[string]interface{}{
+				"primary": map[string]interface{}{"Host": "a", "Port": 80},
+				"backup":  map[string]interface{}{"Host": "b", "Port": 81},
+				"spare":   nil,
+			},
+			"Weights": map[string]interface{}{"primary": 3},
+		}
+		if err := NewDecoder().Decode(data, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := map[string]*Server{"primary": {Host: "a", Port: 80}, "backup": {Host: "b", Port: 81}, "spare": nil}
+		if !reflect.DeepEqual(dst.Servers, want) {
+			t.Errorf("expected %v, got %v", want, dst.Servers)
+		}
+		if dst.Servers["primary"] == dst.Servers["backup"] {
+			t.Error("elements share a pointer")
+		}
+		if w := dst.Weights["primary"]; w == nil || *w != 3 {
+			t.Errorf("unexpected weights: %v", dst.Weights)
+		}
+	})
+
+	t.Run("slice elements", func(t *testing.T) {
+		type Index struct {
+			Postings map[string][]int
+			Matrix   map[string][][]float64
+		}
+		var dst Index
+		data := map[string]interface{}{
+			"Postings": map[string]interface{}{"go": []interface{}{1, 2}, "rust": []int{3}, "none": nil},
+			"Matrix":   map[string][]interface{}{"id": {[]interface{}{1, 0}, []interface{}{0, 1}}},
+		}
+		if err := NewDecoder().Decode(data, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := Index{
+			Postings: map[string][]int{"go": {1, 2}, "rust": {3}, "none": nil},
+			Matrix:   map[string][][]float64{"id": {{1, 0}, {0, 1}}},
+		}
+		if !reflect.DeepEqual(dst, want) {
+			t.Errorf("expected %v, got %v", want, dst)
+		}
+
+		err := NewDecoder().Decode(map[string]interface{}{"Postings": map[string]interface{}{"go": []interface{}{"x"}}}, &dst)
+		if err == nil {
+			t.Error("expected error for incompatible element")
+		}
+	})
+
+	t.Run("incompatible value", func(t *testing.T) {
+		var dst map[string]int
+		if err := NewDecoder().Decode(map[string]interface{}{"a": "x"}, &dst); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestWithAllowInterfaceMapKeys(t *testing.T) {
+	type Database struct {
+		Host string
+		Port int
+	}
+	type Config struct {
+		Name     string
+		Database Database
+		Replicas []Database
+		Labels   map[string]string
+		Codes    map[string]int `gomap:"codes"`
+	}
+
+	// the shape produced by gopkg.in/yaml.v2 for a nested document.
+	src := map[interface{}]interface{}{
+		"Name": "svc",
+		"Database": map[interface{}]interface{}{
+			"Host": "db",
+			"Port": 5432,
+		},
+		"Replicas": []interface{}{
+			map[interface{}]interface{}{"Host": "r1", "Port": 5433},
+		},
+		"Labels": map[interface{}]interface{}{"env": "prod"},
+		"codes":  map[interface{}]interface{}{404: 1, true: 2},
+	}
+
+	var dst Config
+	if err := NewDecoder(WithAllowInterfaceMapKeys()).Decode(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Config{
+		Name:     "svc",
+		Database: Database{Host: "db", Port: 5432},
+		Replicas: []Database{{Host: "r1", Port: 5433}},
+		Labels:   map[string]string{"env": "prod"},
+		Codes:    map[string]int{"404": 1, "true": 2},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("got %+v, want %+v", dst, want)
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		var dst Config
+		if err := NewDecoder().Decode(src, &dst); err == nil {
+			t.Error("expected error for interface{} map keys")
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+)
+
+// assignIP decodes data into out when out is a net.IP, or a pointer to one, and data is a
+// string in dotted-decimal or IPv6 notation, or a byte slice of length 4 or 16 that is copied
+// as is. It reports false for any other combination.
+func (d *Decoder) assignIP(data reflect.Value, out reflect.Value) (bool, error) {
+	if indirectType(out.Type()) != reflect.TypeFor[net.IP]() {
+		return false, nil
+	}
+
+	var value net.IP
+	switch {
+	case data.Kind() == reflect.String:
+		value = net.ParseIP(data.String())
+		if value == nil {
+			return true, fmt.Errorf("invalid IP address %q", data.String())
+		}
+	case isByteSlice(data):
+		if n := data.Len(); n != net.IPv4len && n != net.IPv6len {
+			return true, fmt.Errorf("invalid IP address length %d", n)
+		}
+		value = net.IP(bytes.Clone(data.Bytes()))
+	default:
+		return false, nil
+	}
+	return true, d.setIndirect(out, reflect.ValueOf(value))
+}
+
+// assignURL decodes data into out when out is a url.URL, or a pointer to one, and data is a
+// string parsed with url.Parse or a url.URL. It reports false for any other combination.
+func (d *Decoder) assignURL(data reflect.Value, out reflect.Value) (bool, error) {
+	t := reflect.TypeFor[url.URL]()
+	if indirectType(out.Type()) != t || (data.Kind() != reflect.String && data.Type() != t) {
+		return false, nil
+	}
+
+	if data.Kind() == reflect.Struct {
+		value := data.Interface().(url.URL)
+		if value.User != nil {
+			value.User = cloneUserinfo(value.User)
+		}
+		return true, d.setIndirect(out, reflect.ValueOf(value))
+	}
+	value, err := url.Parse(data.String())
+	if err != nil {
+		return true, fmt.Errorf("invalid URL: %w", err)
+	}
+	return true, d.setIndirect(out, reflect.ValueOf(value).Elem())
+}
+
+// cloneUserinfo returns a copy of u, so that a decoded url.URL shares no pointer with its source.
+func cloneUserinfo(u *url.Userinfo) *url.Userinfo {
+	if password, ok := u.Password(); ok {
+		return url.UserPassword(u.Username(), password)
+	}
+	return url.User(u.Username())
+}
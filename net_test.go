@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+)
+
+func TestIPField(t *testing.T) {
+	type Host struct {
+		Addr net.IP
+		Ptr  *net.IP
+	}
+
+	tests := []struct {
+		name string
+		src  interface{}
+		want net.IP
+	}{
+		{"dotted decimal", "192.168.1.10", net.IPv4(192, 168, 1, 10)},
+		{"ipv6", "2001:db8::1", net.ParseIP("2001:db8::1")},
+		{"4 bytes", []byte{10, 0, 0, 1}, net.IP{10, 0, 0, 1}},
+		{"16 bytes", []byte(net.ParseIP("::1")), net.ParseIP("::1")},
+		{"net.IP", net.IPv4(127, 0, 0, 1), net.IPv4(127, 0, 0, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst Host
+			if err := i2s(map[string]interface{}{"Addr": tt.src, "Ptr": tt.src}, &dst); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !dst.Addr.Equal(tt.want) {
+				t.Errorf("Addr = %v, want %v", dst.Addr, tt.want)
+			}
+			if dst.Ptr == nil || !dst.Ptr.Equal(tt.want) {
+				t.Errorf("Ptr = %v, want %v", dst.Ptr, tt.want)
+			}
+		})
+	}
+
+	t.Run("byte source is copied", func(t *testing.T) {
+		src := []byte{10, 0, 0, 1}
+		var dst Host
+		if err := i2s(map[string]interface{}{"Addr": src}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		src[0] = 99
+		if !dst.Addr.Equal(net.IP{10, 0, 0, 1}) {
+			t.Errorf("Addr = %v, want an independent copy of 10.0.0.1", dst.Addr)
+		}
+	})
+
+	for _, src := range []interface{}{"not an ip", "256.0.0.1", []byte{1, 2, 3}} {
+		var dst Host
+		if err := i2s(map[string]interface{}{"Addr": src}, &dst); err == nil {
+			t.Errorf("expected error for %#v", src)
+		}
+	}
+}
+
+func TestURLField(t *testing.T) {
+	type Endpoint struct {
+		Base     url.URL
+		Callback *url.URL
+	}
+
+	raw := "https://api.example.com:8443/v1/items?limit=10&sort=name"
+	var dst Endpoint
+	if err := i2s(map[string]interface{}{"Base": raw, "Callback": raw}, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Callback == nil {
+		t.Fatal("expected Callback to be allocated")
+	}
+	for name, u := range map[string]*url.URL{"Base": &dst.Base, "Callback": dst.Callback} {
+		if u.Scheme != "https" || u.Host != "api.example.com:8443" || u.Path != "/v1/items" {
+			t.Errorf("%s: unexpected scheme, host or path: %v", name, u)
+		}
+		if q := u.Query(); q.Get("limit") != "10" || q.Get("sort") != "name" {
+			t.Errorf("%s: unexpected query %q", name, u.RawQuery)
+		}
+	}
+
+	err := i2s(map[string]interface{}{"Base": "http://[::1"}, &dst)
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		t.Fatalf("expected *url.Error, got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import "reflect"
+
+// isSQLNull reports whether t is one of the nullable types of database/sql, such as
+// sql.NullString or sql.Null[T]: a struct holding the value followed by a Valid flag.
+func isSQLNull(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == "database/sql" && t.NumField() == 2 &&
+		t.Field(1).Name == "Valid" && t.Field(1).Type.Kind() == reflect.Bool
+}
+
+// assignSQLNull decodes data into out when out is a database/sql nullable type, or a pointer
+// to one. A nil source clears it, leaving Valid false; any other source is decoded into the
+// wrapped value like a plain field of its type, and Valid is set. Nil sources for pointer
+// destinations are left to assignNil. It reports whether out was handled.
+func (d *Decoder) assignSQLNull(data reflect.Value, out reflect.Value) (bool, error) {
+	t := indirectType(out.Type())
+	if !isSQLNull(t) {
+		return false, nil
+	}
+
+	if !data.IsValid() || isNilValue(data) {
+		if out.Kind() == reflect.Pointer || !out.CanSet() {
+			return false, nil
+		}
+		out.SetZero()
+		return true, nil
+	}
+
+	value := reflect.New(t).Elem()
+	if err := d.i2sReflect(data, value.Field(0)); err != nil {
+		return true, err
+	}
+	value.Field(1).SetBool(true)
+	return true, d.setIndirect(out, value)
+}
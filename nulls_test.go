@@ -0,0 +1,86 @@
+package main
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSQLNullTypes(t *testing.T) {
+	type Row struct {
+		Name    sql.NullString
+		Age     sql.NullInt64
+		Score   sql.NullFloat64
+		Active  sql.NullBool
+		Created sql.NullTime
+		Level   sql.Null[int]
+		Note    *sql.NullString
+	}
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	src := map[string]interface{}{
+		"Name":    "john",
+		"Age":     30,
+		"Score":   1.5,
+		"Active":  true,
+		"Created": "2024-01-02T03:04:05Z",
+		"Level":   float64(3),
+		"Note":    "hi",
+	}
+
+	var dst Row
+	if err := NewDecoder().Decode(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Row{
+		Name:    sql.NullString{String: "john", Valid: true},
+		Age:     sql.NullInt64{Int64: 30, Valid: true},
+		Score:   sql.NullFloat64{Float64: 1.5, Valid: true},
+		Active:  sql.NullBool{Bool: true, Valid: true},
+		Created: sql.NullTime{Time: created, Valid: true},
+		Level:   sql.Null[int]{V: 3, Valid: true},
+		Note:    &sql.NullString{String: "hi", Valid: true},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("expected %+v, got %+v", want, dst)
+	}
+
+	t.Run("nil sources", func(t *testing.T) {
+		dst := want
+		src := map[string]interface{}{"Name": nil, "Age": nil, "Level": nil, "Note": nil}
+		if err := NewDecoder().Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Name.Valid || dst.Age.Valid || dst.Level.Valid || dst.Name.String != "" {
+			t.Errorf("expected invalid values, got %+v", dst)
+		}
+		if dst.Note == nil || !dst.Note.Valid {
+			t.Errorf("expected nil source to leave pointer unchanged, got %+v", dst.Note)
+		}
+	})
+
+	t.Run("conversion error", func(t *testing.T) {
+		var dst Row
+		if err := NewDecoder().Decode(map[string]interface{}{"Age": "old"}, &dst); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("encode round trip", func(t *testing.T) {
+		m, err := s2i(Row{Name: sql.NullString{String: "x", Valid: true}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if m["Name"] != "x" || m["Age"] != nil {
+			t.Errorf("unexpected encoding: %v", m)
+		}
+		copied, err := DeepCopy(want)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(copied, want) {
+			t.Errorf("expected %+v, got %+v", want, copied)
+		}
+	})
+}
@@ -0,0 +1,443 @@
+package main
+
+import (
+	"encoding/base64"
+	"os"
+	"reflect"
+	"slices"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// DecoderOptions holds the configuration used by a Decoder.
+type DecoderOptions struct {
+	// Groups restricts decoding to fields tagged with one of these groups.
+	// Untagged fields always belong to the default group and are decoded.
+	Groups []string
+	// Coercions holds kind-level conversions applied before the built-in ones.
+	Coercions *CoercionRegistry
+	// LazyOptional leaves nil pointer-to-struct fields unallocated unless the source
+	// map contains at least one key for the nested struct.
+	LazyOptional bool
+	// CollisionStrategy decides how fields resolving to the same name are handled.
+	CollisionStrategy CollisionStrategy
+	// Expander resolves variable references in string values assigned to string fields.
+	Expander func(string) string
+	// SourceTracking records which source populated each field, see Decoder.FieldSources.
+	SourceTracking bool
+	// Fallback provides values for top-level fields left at their zero value by the source.
+	Fallback map[string]interface{}
+	// NilSource decides what happens when a source value is nil.
+	NilSource NilSourceBehavior
+	// Normalization is the Unicode normalization form applied to strings assigned to string fields.
+	Normalization *norm.Form
+	// ZeroBeforeDecode resets structs and slices to their zero value before decoding into them.
+	ZeroBeforeDecode bool
+	// WeakDecode enables lenient conversions between strings and numbers or bools.
+	WeakDecode bool
+	// APIVersion selects among fields tagged with `version` ranges for the same key.
+	APIVersion *int
+	// FieldTiming records the time spent decoding each field, see Decoder.FieldTimings.
+	FieldTiming bool
+	// Warn receives non-fatal diagnostics, such as unknown source keys.
+	Warn func(msg string)
+	// Preprocessors transform every source map, in order, before it is decoded into a struct.
+	Preprocessors []func(map[string]interface{}) (map[string]interface{}, error)
+	// DeepCopyInterfaces stores deep copies of maps and slices assigned to interface fields.
+	DeepCopyInterfaces bool
+	// Pipeline transforms the top-level source map before decoding.
+	Pipeline []TransformPipeline
+	// StrictTypes disables implicit conversions between different kinds.
+	StrictTypes bool
+	// Allocator allocates the values that nil pointer destinations are set to.
+	Allocator func(t reflect.Type) reflect.Value
+	// CollectErrors keeps decoding after a field fails and reports every failure as DecodeErrors.
+	CollectErrors bool
+	// TimeFormat is the layout used to parse strings into time.Time fields. Defaults to time.RFC3339.
+	TimeFormat string
+	// Strict rejects source keys that have no matching struct field.
+	Strict bool
+	// TagKey is the struct tag key field options are read from. Defaults to "gomap".
+	TagKey string
+	// ZeroMissing resets fields whose keys are absent from the source to their zero value.
+	ZeroMissing bool
+	// MergeMode keeps the current value of fields absent from the source.
+	MergeMode bool
+	// ExpandDotKeys splits dotted keys of the top-level source map into nested maps.
+	ExpandDotKeys bool
+	// KeyTransformer maps source keys without an exact match to the names of untagged fields.
+	KeyTransformer func(string) string
+	// Base64Encoding decodes string sources assigned to untagged []byte fields.
+	Base64Encoding *base64.Encoding
+	// AllowTruncate lets numeric conversions truncate fractions and wrap out-of-range values.
+	AllowTruncate bool
+	// AllowInterfaceMapKeys accepts maps with interface{} keys as struct sources.
+	AllowInterfaceMapKeys bool
+	// MaxDepth limits how deeply nested source values are decoded; 0 means defaultMaxDepth.
+	MaxDepth int
+	// PositionalSlice decodes slices and arrays into structs by position.
+	PositionalSlice bool
+	// AppendSlice appends decoded elements to destination slices instead of replacing them.
+	AppendSlice bool
+	// WrapErrors prefixes returned errors with the type of the destination.
+	WrapErrors bool
+}
+
+// CollisionStrategy controls how a Decoder handles several struct fields that resolve
+// to the same effective name.
+type CollisionStrategy int
+
+const (
+	// CollisionPreferOuter follows Go's promotion rule: the least nested field wins, and
+	// a FieldCollisionError is returned only if several fields share the shallowest depth.
+	CollisionPreferOuter CollisionStrategy = iota
+	// CollisionError returns a FieldCollisionError for every name collision.
+	CollisionError
+)
+
+// NilSourceBehavior controls how a Decoder handles nil source values.
+type NilSourceBehavior int
+
+const (
+	// NilSourceSkip leaves the destination unchanged.
+	NilSourceSkip NilSourceBehavior = iota
+	// NilSourceZero sets the destination to its zero value.
+	NilSourceZero
+	// NilSourceError fails the decode with ErrNilSource.
+	NilSourceError
+)
+
+// Option configures a Decoder.
+type Option func(*DecoderOptions)
+
+// WithGroups restricts decoding to fields tagged with `gomap:",group=<name>"` for one
+// of the given groups. Fields without a group are always decoded.
+func WithGroups(groups ...string) Option {
+	return func(o *DecoderOptions) {
+		o.Groups = append(o.Groups, groups...)
+	}
+}
+
+// inGroups reports whether a field with the given tag should be decoded under the
+// configured groups.
+func (o *DecoderOptions) inGroups(tag fieldTag) bool {
+	group, ok := tag.value("group")
+	if !ok || len(o.Groups) == 0 {
+		return true
+	}
+	return slices.Contains(o.Groups, group)
+}
+
+// WithCoercionRegistry makes the decoder consult r before its built-in kind conversions.
+func WithCoercionRegistry(r *CoercionRegistry) Option {
+	return func(o *DecoderOptions) {
+		o.Coercions = r
+	}
+}
+
+// WithLazyOptional only allocates nil pointer-to-struct fields when the source map has
+// at least one key that would populate the nested struct.
+func WithLazyOptional() Option {
+	return func(o *DecoderOptions) {
+		o.LazyOptional = true
+	}
+}
+
+// WithCollisionStrategy sets how fields resolving to the same name are handled.
+// The default is CollisionPreferOuter.
+func WithCollisionStrategy(s CollisionStrategy) Option {
+	return func(o *DecoderOptions) {
+		o.CollisionStrategy = s
+	}
+}
+
+// WithEnvExpansion expands `${VAR}` and `$VAR` references in string fields using os.Getenv.
+// Undefined variables expand to the empty string.
+func WithEnvExpansion() Option {
+	return WithExpander(os.Getenv)
+}
+
+// WithExpander expands `${VAR}` and `$VAR` references in string fields using fn.
+func WithExpander(fn func(string) string) Option {
+	return func(o *DecoderOptions) {
+		o.Expander = fn
+	}
+}
+
+// WithSourceTracking records, for every decoded field, the index of the source and the key
+// it was read from. The result is available through Decoder.FieldSources.
+func WithSourceTracking() Option {
+	return func(o *DecoderOptions) {
+		o.SourceTracking = true
+	}
+}
+
+// WithFallback supplies default values for top-level struct fields. After the source has
+// been decoded, every fallback key whose field is still at its zero value is applied.
+func WithFallback(fallback map[string]interface{}) Option {
+	return func(o *DecoderOptions) {
+		o.Fallback = fallback
+	}
+}
+
+// WithNilSourceBehavior sets how nil source values are handled. The default is NilSourceSkip.
+func WithNilSourceBehavior(b NilSourceBehavior) Option {
+	return func(o *DecoderOptions) {
+		o.NilSource = b
+	}
+}
+
+// WithUnicodeNormalization normalizes strings assigned to string fields to the given form
+// (norm.NFC, norm.NFD, norm.NFKC or norm.NFKD), so that canonically or compatibly equivalent
+// inputs decode to identical values.
+func WithUnicodeNormalization(form norm.Form) Option {
+	return func(o *DecoderOptions) {
+		o.Normalization = &form
+	}
+}
+
+// WithZeroBeforeDecode zeroes destination structs and slices before decoding into them, so
+// that fields absent from the source end up at their zero value instead of keeping
+// pre-existing values.
+func WithZeroBeforeDecode() Option {
+	return func(o *DecoderOptions) {
+		o.ZeroBeforeDecode = true
+	}
+}
+
+// WithWeakDecode enables lenient conversions for data from query strings, CSV files and similar
+// text sources: strings are parsed into numeric fields with strconv and into bool fields from
+// "true"/"false", "1"/"0" or "yes"/"no"; numbers are formatted into string fields; and
+// destinations implementing fmt.Scanner are parsed with fmt.Sscan.
+func WithWeakDecode() Option {
+	return func(o *DecoderOptions) {
+		o.WeakDecode = true
+	}
+}
+
+// WithAPIVersion selects, among fields tagged `gomap:"name,version=N"` for the same key,
+// the one whose version range includes v. Keys with no matching field are treated as unknown.
+func WithAPIVersion(v int) Option {
+	return func(o *DecoderOptions) {
+		o.APIVersion = &v
+	}
+}
+
+// WithFieldTiming records how long each field takes to decode, to help locate slow
+// conversions. The result is available through Decoder.FieldTimings.
+func WithFieldTiming() Option {
+	return func(o *DecoderOptions) {
+		o.FieldTiming = true
+	}
+}
+
+// WithWarningHandler passes non-fatal diagnostics to fn. Unknown source keys are reported
+// with the closest matching field name, e.g. "unknown key 'keystirng', did you mean 'KeyString'?".
+func WithWarningHandler(fn func(msg string)) Option {
+	return func(o *DecoderOptions) {
+		o.Warn = fn
+	}
+}
+
+// WithPreprocess adds fn to the functions applied to every source map before it is decoded
+// into a struct, including nested ones. Each call appends to the chain. The first function
+// receives a shallow copy of the source, so the caller's map is never modified.
+func WithPreprocess(fn func(map[string]interface{}) (map[string]interface{}, error)) Option {
+	return func(o *DecoderOptions) {
+		o.Preprocessors = append(o.Preprocessors, fn)
+	}
+}
+
+// WithDeepCopyInterfaces deep-copies maps and slices before storing them in interface{}
+// fields, so that later changes to the source do not show through the decoded struct.
+// Without it the interface field shares the source's map or slice.
+func WithDeepCopyInterfaces() Option {
+	return func(o *DecoderOptions) {
+		o.DeepCopyInterfaces = true
+	}
+}
+
+// WithPipeline applies the given transformers, in order, to the top-level source map before
+// it is decoded. Unlike WithPreprocess, nested maps are not transformed.
+func WithPipeline(transformers ...TransformPipeline) Option {
+	return func(o *DecoderOptions) {
+		o.Pipeline = append(o.Pipeline, transformers...)
+	}
+}
+
+// WithStrictTypes disables every implicit conversion between scalar kinds: a source value is
+// only assigned to a destination of exactly the same kind, e.g. int64 to int64 or float64 to
+// float64. Any other combination fails with a StrictTypeMismatchError. Conversions registered
+// with WithCoercionRegistry still apply.
+func WithStrictTypes() Option {
+	return func(o *DecoderOptions) {
+		o.StrictTypes = true
+	}
+}
+
+// WithAllocator makes the decoder call fn(t) instead of reflect.New(t) whenever it sets a nil
+// pointer destination of type *t, e.g. to take nested structs from a sync.Pool or an arena.
+// fn must return a non-nil pointer of type *t; the decoder does not reset the value it points to.
+func WithAllocator(fn func(t reflect.Type) reflect.Value) Option {
+	return func(o *DecoderOptions) {
+		o.Allocator = fn
+	}
+}
+
+// WithCollectErrors keeps decoding the remaining fields when one fails and returns all
+// per-field failures together as a DecodeErrors once the whole source has been processed.
+// Errors that prevent decoding altogether, such as a source that is not a map, are still
+// returned immediately.
+func WithCollectErrors() Option {
+	return func(o *DecoderOptions) {
+		o.CollectErrors = true
+	}
+}
+
+// WithTimeFormat sets the layout, as understood by time.Parse, used to parse strings into
+// time.Time fields. The default is time.RFC3339.
+func WithTimeFormat(layout string) Option {
+	return func(o *DecoderOptions) {
+		o.TimeFormat = layout
+	}
+}
+
+// WithStrictMode makes decoding fail with an *UnknownKeysError listing every source key that
+// has no matching struct field, with the closest field name suggested for each. Keys of fields
+// tagged decode_ignore or left out by WithGroups are not unknown; fields tagged `gomap:"-"`
+// have no key at all.
+func WithStrictMode() Option {
+	return func(o *DecoderOptions) {
+		o.Strict = true
+	}
+}
+
+// WithTagKey reads field names and options from the struct tag key k instead of "gomap",
+// e.g. WithTagKey("json") to reuse existing `json:"name"` tags.
+func WithTagKey(k string) Option {
+	return func(o *DecoderOptions) {
+		o.TagKey = k
+	}
+}
+
+// tagKey returns the struct tag key field options are read from.
+func (o *DecoderOptions) tagKey() string {
+	if o.TagKey == "" {
+		return defaultTagKey
+	}
+	return o.TagKey
+}
+
+// WithZeroMissing sets every decodable struct field whose key is absent from the source map to
+// its zero value, so that a reused struct only holds what the latest source provided. Unlike
+// WithZeroBeforeDecode it leaves fields tagged decode_ignore or outside WithGroups untouched.
+func WithZeroMissing() Option {
+	return func(o *DecoderOptions) {
+		o.ZeroMissing = true
+	}
+}
+
+// WithMergeMode decodes with patch semantics: fields whose keys are absent from the source keep
+// their current value, so a pre-populated struct is only partially updated. Unlike the default
+// behaviour, `default` tag options are not applied to such fields; merge mode also overrides
+// WithZeroMissing and WithZeroBeforeDecode.
+func WithMergeMode() Option {
+	return func(o *DecoderOptions) {
+		o.MergeMode = true
+	}
+}
+
+// WithExpandDotKeys splits the dot-separated keys of the top-level source map into nested maps
+// before decoding, so that {"db.host": "localhost", "db.port": 5432} decodes into a struct with
+// a DB field holding Host and Port. It runs after the WithPipeline transformers.
+func WithExpandDotKeys() Option {
+	return func(o *DecoderOptions) {
+		o.ExpandDotKeys = true
+	}
+}
+
+// WithKeyTransformer passes every source key that matches no field exactly through fn before
+// looking it up again, e.g. WithKeyTransformer(SnakeToCamel) to decode "user_name" into
+// UserName. A transformed key matches an untagged field whose name, as is or passed through
+// fn as well, equals it: WithKeyTransformer(CamelToSnake) decodes "userName" into UserName.
+// Tag names are always used verbatim.
+func WithKeyTransformer(fn func(string) string) Option {
+	return func(o *DecoderOptions) {
+		o.KeyTransformer = fn
+	}
+}
+
+// WithBase64Encoding decodes strings assigned to []byte fields with enc, e.g.
+// base64.StdEncoding or base64.RawURLEncoding, instead of storing their UTF-8 bytes. Fields
+// tagged `hex`, `base64` or `base64url` keep using the encoding of their tag.
+func WithBase64Encoding(enc *base64.Encoding) Option {
+	return func(o *DecoderOptions) {
+		o.Base64Encoding = enc
+	}
+}
+
+// WithAllowTruncate restores the unchecked numeric conversions: floats with a fractional part
+// are truncated when stored in integer fields, and values outside the range of the destination
+// wrap around, as in a Go conversion. By default both are reported as errors.
+func WithAllowTruncate() Option {
+	return func(o *DecoderOptions) {
+		o.AllowTruncate = true
+	}
+}
+
+// WithAllowInterfaceMapKeys lets maps with interface{} keys, such as the
+// map[interface{}]interface{} values produced by gopkg.in/yaml.v2, be decoded into structs.
+// Each key is formatted with %v to find its field, so a YAML key 1 matches a field named "1".
+func WithAllowInterfaceMapKeys() Option {
+	return func(o *DecoderOptions) {
+		o.AllowInterfaceMapKeys = true
+	}
+}
+
+// WithMaxDepth sets how many levels of nested maps, slices, arrays, structs and pointers the
+// decoder descends into before failing with ErrMaxDepthExceeded. The default is 100. Sources
+// that contain themselves fail with ErrCyclicReference regardless of the limit.
+func WithMaxDepth(n int) Option {
+	return func(o *DecoderOptions) {
+		o.MaxDepth = n
+	}
+}
+
+// WithPositionalSlice decodes slice and array sources into struct destinations by position,
+// as if every struct had a field tagged `gomap:",pos"`: element 0 goes to the first exported
+// field, element 1 to the second and so on. This suits CSV rows and tuples.
+func WithPositionalSlice() Option {
+	return func(o *DecoderOptions) {
+		o.PositionalSlice = true
+	}
+}
+
+// WithAppendSlice appends the decoded elements of slice sources to the elements already held
+// by destination slices, at any depth, instead of replacing them, so that data arriving in
+// batches can be accumulated by decoding each batch into the same struct.
+func WithAppendSlice() Option {
+	return func(o *DecoderOptions) {
+		o.AppendSlice = true
+	}
+}
+
+// WithWrapErrors wraps every error returned by the methods decoding into a destination as
+// "Decode into *T: err", where *T is the type of the destination, so that logs can be searched
+// by destination type. The original error stays available to errors.Is and errors.As.
+func WithWrapErrors() Option {
+	return func(o *DecoderOptions) {
+		o.WrapErrors = true
+	}
+}
+
+// stringValue applies the configured Unicode normalization and variable expansion to a
+// string that is about to be assigned to a string field.
+func (o *DecoderOptions) stringValue(s string) string {
+	if o.Normalization != nil {
+		s = o.Normalization.String(s)
+	}
+	if o.Expander != nil {
+		s = os.Expand(s, o.Expander)
+	}
+	return s
+}
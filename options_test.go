@@ -0,0 +1,883 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestWithGroups(t *testing.T) {
+	type Account struct {
+		Name     string `gomap:",group=public"`
+		Email    string `gomap:",group=admin"`
+		Password string `gomap:",group=admin"`
+		ID       int
+	}
+
+	src := map[string]interface{}{
+		"Name":     "john",
+		"Email":    "john@example.com",
+		"Password": "secret",
+		"ID":       7,
+	}
+
+	t.Run("public group", func(t *testing.T) {
+		var dst Account
+		err := NewDecoder(WithGroups("public")).Decode(src, &dst)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Name != "john" || dst.ID != 7 {
+			t.Errorf("expected public and untagged fields, got %+v", dst)
+		}
+		if dst.Email != "" || dst.Password != "" {
+			t.Errorf("expected admin fields to be skipped, got %+v", dst)
+		}
+	})
+
+	t.Run("all groups", func(t *testing.T) {
+		var dst Account
+		err := NewDecoder(WithGroups("public", "admin")).Decode(src, &dst)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := Account{Name: "john", Email: "john@example.com", Password: "secret", ID: 7}
+		if dst != want {
+			t.Errorf("expected %+v, got %+v", want, dst)
+		}
+	})
+
+	t.Run("no groups configured", func(t *testing.T) {
+		var dst Account
+		err := NewDecoder().Decode(src, &dst)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Email != "john@example.com" || dst.Name != "john" {
+			t.Errorf("expected all fields without groups option, got %+v", dst)
+		}
+	})
+
+	t.Run("unknown group", func(t *testing.T) {
+		var dst Account
+		err := NewDecoder(WithGroups("other")).Decode(src, &dst)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst != (Account{ID: 7}) {
+			t.Errorf("expected only untagged field, got %+v", dst)
+		}
+	})
+}
+
+func TestWithLazyOptional(t *testing.T) {
+	type Config struct {
+		Host string
+		Port int
+	}
+	type Service struct {
+		Name   string
+		Config *Config
+	}
+
+	tests := []struct {
+		name    string
+		src     map[string]interface{}
+		opts    []Option
+		wantNil bool
+	}{
+		{"empty nested map", map[string]interface{}{"Config": map[string]interface{}{}}, nil, false},
+		{"empty nested map lazy", map[string]interface{}{"Config": map[string]interface{}{}}, []Option{WithLazyOptional()}, true},
+		{"unknown nested keys lazy", map[string]interface{}{"Config": map[string]interface{}{"Other": 1}}, []Option{WithLazyOptional()}, true},
+		{"absent nested key lazy", map[string]interface{}{"Name": "svc"}, []Option{WithLazyOptional()}, true},
+		{"populated nested map lazy", map[string]interface{}{"Config": map[string]interface{}{"Host": "localhost"}}, []Option{WithLazyOptional()}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst Service
+			if err := NewDecoder(tt.opts...).Decode(tt.src, &dst); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (dst.Config == nil) != tt.wantNil {
+				t.Errorf("Config = %+v, want nil: %v", dst.Config, tt.wantNil)
+			}
+		})
+	}
+
+	t.Run("populated values", func(t *testing.T) {
+		var dst Service
+		src := map[string]interface{}{"Config": map[string]interface{}{"Host": "localhost", "Port": 80}}
+		if err := NewDecoder(WithLazyOptional()).Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Config.Host != "localhost" || dst.Config.Port != 80 {
+			t.Errorf("unexpected result: %+v", dst.Config)
+		}
+	})
+}
+
+func TestWithEnvExpansion(t *testing.T) {
+	type Config struct {
+		Host  string
+		Path  string
+		Plain string
+		Port  int
+	}
+
+	src := map[string]interface{}{
+		"Host":  "${GOSTRUCTMAP_TEST_HOST}",
+		"Path":  "/srv/$GOSTRUCTMAP_TEST_UNDEFINED/data",
+		"Plain": "no variables",
+		"Port":  8080,
+	}
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv("GOSTRUCTMAP_TEST_HOST", "example.com")
+		var dst Config
+		if err := NewDecoder(WithEnvExpansion()).Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := Config{Host: "example.com", Path: "/srv//data", Plain: "no variables", Port: 8080}
+		if dst != want {
+			t.Errorf("expected %+v, got %+v", want, dst)
+		}
+	})
+
+	t.Run("custom expander", func(t *testing.T) {
+		env := map[string]string{"GOSTRUCTMAP_TEST_HOST": "mock.local"}
+		expander := func(key string) string { return env[key] }
+		var dst Config
+		if err := NewDecoder(WithExpander(expander)).Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Host != "mock.local" || dst.Path != "/srv//data" {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		var dst Config
+		if err := NewDecoder().Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Host != "${GOSTRUCTMAP_TEST_HOST}" {
+			t.Errorf("expected raw value, got %q", dst.Host)
+		}
+	})
+}
+
+func TestWithFallback(t *testing.T) {
+	type Config struct {
+		Host    string
+		Port    int
+		Debug   bool
+		Timeout float64
+	}
+
+	fallback := map[string]interface{}{
+		"Host":    "localhost",
+		"Port":    80,
+		"Debug":   true,
+		"Timeout": 1.5,
+	}
+	src := map[string]interface{}{
+		"Host": "example.com",
+		"Port": 8080,
+	}
+
+	var dst Config
+	if err := NewDecoder(WithFallback(fallback)).Decode(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Config{Host: "example.com", Port: 8080, Debug: true, Timeout: 1.5}
+	if dst != want {
+		t.Errorf("expected %+v, got %+v", want, dst)
+	}
+
+	t.Run("invalid fallback value", func(t *testing.T) {
+		var dst Config
+		err := NewDecoder(WithFallback(map[string]interface{}{"Port": "x"})).Decode(map[string]interface{}{}, &dst)
+		if err == nil {
+			t.Error("expected error for invalid fallback value")
+		}
+	})
+}
+
+func TestWithNilSourceBehavior(t *testing.T) {
+	type Record struct {
+		Name  string
+		Count *int
+	}
+
+	src := map[string]interface{}{"Name": nil, "Count": nil}
+	count := 3
+
+	t.Run("skip", func(t *testing.T) {
+		dst := Record{Name: "old", Count: &count}
+		if err := NewDecoder(WithNilSourceBehavior(NilSourceSkip)).Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Name != "old" || dst.Count != &count {
+			t.Errorf("expected fields to be unchanged, got %+v", dst)
+		}
+	})
+
+	t.Run("zero", func(t *testing.T) {
+		dst := Record{Name: "old", Count: &count}
+		if err := NewDecoder(WithNilSourceBehavior(NilSourceZero)).Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Name != "" || dst.Count != nil {
+			t.Errorf("expected zero fields, got %+v", dst)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		dst := Record{Name: "old"}
+		err := NewDecoder(WithNilSourceBehavior(NilSourceError)).Decode(map[string]interface{}{"Name": nil}, &dst)
+		if !errors.Is(err, ErrNilSource) {
+			t.Errorf("expected ErrNilSource, got %v", err)
+		}
+	})
+}
+
+func TestWithUnicodeNormalization(t *testing.T) {
+	type Profile struct {
+		Name string
+	}
+
+	const (
+		precomposed = "caf\u00e9"  // é as a single code point
+		decomposed  = "cafe\u0301" // e followed by a combining acute accent
+		ligature    = "\ufb01le"   // "fi" ligature
+	)
+
+	tests := []struct {
+		name string
+		form norm.Form
+		src  string
+		want string
+	}{
+		{"NFC composes", norm.NFC, decomposed, precomposed},
+		{"NFC keeps precomposed", norm.NFC, precomposed, precomposed},
+		{"NFD decomposes", norm.NFD, precomposed, decomposed},
+		{"NFKC composes", norm.NFKC, decomposed, precomposed},
+		{"NFKC folds compatibility", norm.NFKC, ligature, "file"},
+		{"NFC keeps compatibility", norm.NFC, ligature, ligature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst Profile
+			err := NewDecoder(WithUnicodeNormalization(tt.form)).Decode(map[string]interface{}{"Name": tt.src}, &dst)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if dst.Name != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, dst.Name)
+			}
+		})
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		var dst Profile
+		if err := NewDecoder().Decode(map[string]interface{}{"Name": decomposed}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Name != decomposed {
+			t.Errorf("expected input to be unchanged, got %q", dst.Name)
+		}
+	})
+}
+
+func TestWithZeroBeforeDecode(t *testing.T) {
+	type Config struct {
+		Host string
+		Port int
+		Tags []string
+	}
+
+	src := map[string]interface{}{"Host": "example.com"}
+
+	t.Run("enabled", func(t *testing.T) {
+		dst := Config{Host: "old", Port: 80, Tags: []string{"a"}}
+		if err := NewDecoder(WithZeroBeforeDecode()).Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Host != "example.com" || dst.Port != 0 || dst.Tags != nil {
+			t.Errorf("expected absent fields to be zero, got %+v", dst)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		dst := Config{Host: "old", Port: 80}
+		if err := NewDecoder().Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Host != "example.com" || dst.Port != 80 {
+			t.Errorf("expected absent fields to be preserved, got %+v", dst)
+		}
+	})
+
+	t.Run("slice", func(t *testing.T) {
+		dst := []int{1, 2, 3}
+		if err := NewDecoder(WithZeroBeforeDecode()).Decode([]interface{}{4}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(dst) != 1 || dst[0] != 4 {
+			t.Errorf("unexpected result: %v", dst)
+		}
+	})
+}
+
+func TestWithPreprocess(t *testing.T) {
+	type Address struct {
+		City string `gomap:"city"`
+	}
+	type User struct {
+		Name    string  `gomap:"name"`
+		Age     int     `gomap:"age"`
+		Address Address `gomap:"address"`
+	}
+
+	lowercase := func(m map[string]interface{}) (map[string]interface{}, error) {
+		for k, v := range m {
+			delete(m, k)
+			m[strings.ToLower(k)] = v
+		}
+		return m, nil
+	}
+
+	src := map[string]interface{}{
+		"NAME":    "john",
+		"Age":     30,
+		"Address": map[string]interface{}{"CITY": "Paris"},
+	}
+
+	var dst User
+	if err := NewDecoder(WithPreprocess(lowercase)).Decode(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := User{Name: "john", Age: 30, Address: Address{City: "Paris"}}
+	if dst != want {
+		t.Errorf("expected %+v, got %+v", want, dst)
+	}
+	if _, ok := src["NAME"]; !ok {
+		t.Error("expected caller's map to be left unmodified")
+	}
+
+	t.Run("chained in order", func(t *testing.T) {
+		var order []string
+		step := func(name string) func(map[string]interface{}) (map[string]interface{}, error) {
+			return func(m map[string]interface{}) (map[string]interface{}, error) {
+				order = append(order, name)
+				return m, nil
+			}
+		}
+		var dst User
+		if err := NewDecoder(WithPreprocess(step("a")), WithPreprocess(step("b"))).Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if strings.Join(order, "") != "ab" {
+			t.Errorf("expected preprocessors to run in order, got %v", order)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		fail := func(map[string]interface{}) (map[string]interface{}, error) { return nil, errBoom }
+		var dst User
+		if err := NewDecoder(WithPreprocess(fail)).Decode(src, &dst); !errors.Is(err, errBoom) {
+			t.Errorf("expected preprocessor error, got %v", err)
+		}
+	})
+}
+
+func TestWithStrictTypes(t *testing.T) {
+	type Values struct {
+		Count int64
+		Ratio float64
+	}
+
+	tests := []struct {
+		name    string
+		src     map[string]interface{}
+		wantErr bool
+	}{
+		{"int64 to int64", map[string]interface{}{"Count": int64(3)}, false},
+		{"float64 to float64", map[string]interface{}{"Ratio": 0.5}, false},
+		{"int8 to int64", map[string]interface{}{"Count": int8(3)}, true},
+		{"int to int64", map[string]interface{}{"Count": 3}, true},
+		{"int64 to float64", map[string]interface{}{"Ratio": int64(1)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst Values
+			err := NewDecoder(WithStrictTypes()).Decode(tt.src, &dst)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var mismatch *StrictTypeMismatchError
+			if !errors.As(err, &mismatch) {
+				t.Fatalf("expected StrictTypeMismatchError, got %v", err)
+			}
+			for key := range tt.src {
+				if mismatch.Field != key {
+					t.Errorf("expected field %q, got %q", key, mismatch.Field)
+				}
+				if !strings.Contains(err.Error(), fmt.Sprintf("field %q", key)) {
+					t.Errorf("expected the message to name field %q, got %q", key, err.Error())
+				}
+			}
+		})
+	}
+
+	t.Run("int8 to int64 without strict types", func(t *testing.T) {
+		var dst Values
+		if err := NewDecoder().Decode(map[string]interface{}{"Count": int8(3)}, &dst); err != nil || dst.Count != 3 {
+			t.Errorf("expected implicit conversion, got %+v, %v", dst, err)
+		}
+	})
+}
+
+func TestWithCollectErrors(t *testing.T) {
+	type Address struct {
+		City string
+		Zip  int
+	}
+	type User struct {
+		Name    string
+		Age     int
+		Active  bool
+		Address Address
+	}
+
+	src := map[string]interface{}{
+		"Name":    "john",
+		"Age":     "thirty",
+		"Active":  "yes",
+		"Address": map[string]interface{}{"City": 1, "Zip": 12345},
+	}
+
+	t.Run("collects every field error", func(t *testing.T) {
+		var dst User
+		err := NewDecoder(WithCollectErrors()).Decode(src, &dst)
+
+		var errs DecodeErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("expected DecodeErrors, got %v", err)
+		}
+		if len(errs) != 3 {
+			t.Fatalf("expected 3 errors, got %d: %v", len(errs), errs)
+		}
+		for _, field := range []string{`"Age"`, `"Active"`, `"Address.City"`} {
+			if !strings.Contains(err.Error(), field) {
+				t.Errorf("expected error to mention %s, got %v", field, err)
+			}
+		}
+		if dst.Name != "john" || dst.Address.Zip != 12345 {
+			t.Errorf("expected valid fields to be decoded, got %+v", dst)
+		}
+	})
+
+	t.Run("stops at first error by default", func(t *testing.T) {
+		var dst User
+		err := NewDecoder().Decode(src, &dst)
+		var errs DecodeErrors
+		if err == nil || errors.As(err, &errs) {
+			t.Errorf("expected a single error, got %v", err)
+		}
+	})
+
+	t.Run("invalid source still fails early", func(t *testing.T) {
+		var dst User
+		err := NewDecoder(WithCollectErrors()).Decode(map[int]interface{}{1: "x"}, &dst)
+		var errs DecodeErrors
+		if err == nil || errors.As(err, &errs) {
+			t.Errorf("expected an immediate error, got %v", err)
+		}
+	})
+
+	t.Run("no errors", func(t *testing.T) {
+		var dst User
+		if err := NewDecoder(WithCollectErrors()).Decode(map[string]interface{}{"Name": "john"}, &dst); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestWithStrictMode(t *testing.T) {
+	type User struct {
+		Name     string
+		Age      int
+		Internal string `gomap:",decode_ignore"`
+	}
+
+	src := map[string]interface{}{"Name": "john", "nmae": "x", "Internal": "y", "email": "a@b.c"}
+
+	for name, data := range map[string]interface{}{"fast path": src, "reflection path": genericMap(src)} {
+		t.Run(name, func(t *testing.T) {
+			var dst User
+			err := NewDecoder(WithStrictMode()).Decode(data, &dst)
+
+			var unknown *UnknownKeysError
+			if !errors.As(err, &unknown) {
+				t.Fatalf("expected UnknownKeysError, got %v", err)
+			}
+			if strings.Join(unknown.Keys, ",") != "email,nmae" {
+				t.Errorf("unexpected keys: %v", unknown.Keys)
+			}
+			want := "unknown keys: 'email', 'nmae' (did you mean 'Name'?)"
+			if err.Error() != want {
+				t.Errorf("error = %q, want %q", err.Error(), want)
+			}
+		})
+	}
+
+	t.Run("known keys only", func(t *testing.T) {
+		var dst User
+		if err := NewDecoder(WithStrictMode()).Decode(map[string]interface{}{"Name": "john", "Age": 3}, &dst); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("lenient by default", func(t *testing.T) {
+		var dst User
+		if err := NewDecoder().Decode(src, &dst); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestWithWeakDecode(t *testing.T) {
+	type Params struct {
+		Page    int
+		Limit   uint8
+		Ratio   float64
+		Enabled bool
+		Label   string
+		Count   *int
+	}
+
+	t.Run("conversions", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			src   map[string]interface{}
+			check func(p Params) bool
+		}{
+			{"string to int", map[string]interface{}{"Page": "42"}, func(p Params) bool { return p.Page == 42 }},
+			{"string to uint", map[string]interface{}{"Limit": "200"}, func(p Params) bool { return p.Limit == 200 }},
+			{"string to float", map[string]interface{}{"Ratio": "0.25"}, func(p Params) bool { return p.Ratio == 0.25 }},
+			{"string to pointer", map[string]interface{}{"Count": "7"}, func(p Params) bool { return *p.Count == 7 }},
+			{"yes to bool", map[string]interface{}{"Enabled": "YES"}, func(p Params) bool { return p.Enabled }},
+			{"1 to bool", map[string]interface{}{"Enabled": "1"}, func(p Params) bool { return p.Enabled }},
+			{"int to string", map[string]interface{}{"Label": 42}, func(p Params) bool { return p.Label == "42" }},
+			{"float to string", map[string]interface{}{"Label": 1.5}, func(p Params) bool { return p.Label == "1.5" }},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var dst Params
+				if err := NewDecoder(WithWeakDecode()).Decode(tt.src, &dst); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !tt.check(dst) {
+					t.Errorf("unexpected result: %+v", dst)
+				}
+			})
+		}
+	})
+
+	t.Run("invalid strings", func(t *testing.T) {
+		for _, src := range []map[string]interface{}{
+			{"Page": "x"},
+			{"Limit": "300"},
+			{"Enabled": "maybe"},
+		} {
+			var dst Params
+			if err := NewDecoder(WithWeakDecode()).Decode(src, &dst); err == nil {
+				t.Errorf("%v: expected error", src)
+			}
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		var dst Params
+		if err := NewDecoder().Decode(map[string]interface{}{"Page": "42"}, &dst); err == nil {
+			t.Error("expected error without weak decoding")
+		}
+		if err := NewDecoder().Decode(map[string]interface{}{"Label": 42}, &dst); err == nil {
+			t.Error("expected error without weak decoding")
+		}
+	})
+}
+
+func TestWithTagKey(t *testing.T) {
+	type User struct {
+		ID   int    `json:"id" gomap:"user_id"`
+		Name string `json:"name,omitempty"`
+		Skip string `json:"-"`
+	}
+
+	src := map[string]interface{}{"id": 1, "user_id": 2, "name": "john", "Name": "jane", "Skip": "x"}
+
+	var dst User
+	if err := NewDecoder(WithTagKey("json")).Decode(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst != (User{ID: 1, Name: "john"}) {
+		t.Errorf("unexpected result with json tags: %+v", dst)
+	}
+
+	// the same type decoded with the default tag key must not reuse the json metadata.
+	dst = User{}
+	if err := NewDecoder().Decode(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst != (User{ID: 2, Name: "jane", Skip: "x"}) {
+		t.Errorf("unexpected result with gomap tags: %+v", dst)
+	}
+}
+
+func TestWithZeroMissing(t *testing.T) {
+	type Address struct {
+		City string
+		Zip  string
+	}
+	type User struct {
+		Name     string
+		Age      int
+		Internal string `gomap:",decode_ignore"`
+		Address  Address
+	}
+
+	src := map[string]interface{}{"Name": "john", "Address": map[string]interface{}{"City": "Berlin"}}
+
+	for name, data := range map[string]interface{}{"fast path": src, "reflection path": genericMap(src)} {
+		t.Run(name, func(t *testing.T) {
+			dst := User{Name: "old", Age: 30, Internal: "keep", Address: Address{City: "Paris", Zip: "75001"}}
+			if err := NewDecoder(WithZeroMissing()).Decode(data, &dst); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want := User{Name: "john", Internal: "keep", Address: Address{City: "Berlin"}}
+			if dst != want {
+				t.Errorf("expected %+v, got %+v", want, dst)
+			}
+		})
+	}
+}
+
+func TestWithMergeMode(t *testing.T) {
+	type Settings struct {
+		Theme    string
+		FontSize int
+		Plugins  []string
+		Editor   struct {
+			TabSize int
+			Wrap    bool
+		}
+	}
+
+	current := Settings{Theme: "dark", FontSize: 12, Plugins: []string{"git"}}
+	current.Editor.TabSize = 4
+	current.Editor.Wrap = true
+
+	patch := map[string]interface{}{
+		"FontSize": 14,
+		"Editor":   map[string]interface{}{"TabSize": 2},
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"default", nil},
+		{"merge mode", []Option{WithMergeMode()}},
+		{"overrides zero missing", []Option{WithMergeMode(), WithZeroMissing()}},
+		{"overrides zero before decode", []Option{WithMergeMode(), WithZeroBeforeDecode()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := current
+			dst.Plugins = slices.Clone(current.Plugins)
+			if err := NewDecoder(tt.opts...).Decode(patch, &dst); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if dst.Theme != "dark" || dst.FontSize != 14 || !slices.Equal(dst.Plugins, []string{"git"}) ||
+				dst.Editor.TabSize != 2 || !dst.Editor.Wrap {
+				t.Errorf("unexpected result: %+v", dst)
+			}
+		})
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		type Limits struct {
+			Retries int `gomap:"retries,default=3"`
+			Burst   int `gomap:"burst,default=10"`
+		}
+
+		dst := Limits{Retries: 5}
+		if err := NewDecoder().Decode(map[string]interface{}{}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst != (Limits{Retries: 3, Burst: 10}) {
+			t.Errorf("expected defaults without merge mode, got %+v", dst)
+		}
+
+		dst = Limits{Retries: 5}
+		if err := NewDecoder(WithMergeMode()).Decode(map[string]interface{}{"burst": 20}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst != (Limits{Retries: 5, Burst: 20}) {
+			t.Errorf("expected merge mode to skip defaults, got %+v", dst)
+		}
+	})
+}
+
+func TestWithAllowTruncate(t *testing.T) {
+	type Numbers struct {
+		Small  int8
+		Count  int
+		Port   uint16
+		Ratio  float32
+		Offset uint
+	}
+
+	invalid := []struct {
+		name string
+		src  map[string]interface{}
+		msg  string
+	}{
+		{"int overflows int8", map[string]interface{}{"Small": 300}, "value 300 overflows int8"},
+		{"negative int overflows int8", map[string]interface{}{"Small": -129}, "value -129 overflows int8"},
+		{"uint overflows uint16", map[string]interface{}{"Port": uint64(70000)}, "value 70000 overflows uint16"},
+		{"fractional float", map[string]interface{}{"Count": 1.5}, "fractional part"},
+		{"float overflows int8", map[string]interface{}{"Small": 200.0}, "value 200 overflows int8"},
+		{"float overflows int", map[string]interface{}{"Count": 1e19}, "overflows int"},
+		{"negative float into uint", map[string]interface{}{"Offset": -1.0}, "value -1 overflows uint"},
+		{"float64 overflows float32", map[string]interface{}{"Ratio": 1e39}, "overflows float32"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst Numbers
+			err := i2s(tt.src, &dst)
+			if err == nil || !strings.Contains(err.Error(), tt.msg) {
+				t.Fatalf("expected error containing %q, got %v", tt.msg, err)
+			}
+		})
+	}
+
+	t.Run("in range", func(t *testing.T) {
+		var dst Numbers
+		src := map[string]interface{}{"Small": -128, "Count": 42.0, "Port": 8080, "Ratio": 0.5, "Offset": 7.0}
+		if err := i2s(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Small != -128 || dst.Count != 42 || dst.Port != 8080 || dst.Ratio != 0.5 || dst.Offset != 7 {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("allow truncate", func(t *testing.T) {
+		var dst Numbers
+		src := map[string]interface{}{"Small": 300, "Count": 1.9, "Port": uint64(70000)}
+		if err := NewDecoder(WithAllowTruncate()).Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Small != 44 || dst.Count != 1 || dst.Port != 4464 {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+}
+
+func TestWithAppendSlice(t *testing.T) {
+	type Batch struct {
+		Name  string
+		IDs   []int
+		Raw   []byte
+		Pairs [][]string
+	}
+
+	d := NewDecoder(WithAppendSlice())
+	var dst Batch
+	batches := []map[string]interface{}{
+		{"Name": "a", "IDs": []interface{}{1, 2}, "Raw": []byte("ab"), "Pairs": []interface{}{[]interface{}{"x"}}},
+		{"Name": "b", "IDs": []interface{}{3}, "Raw": []byte("c"), "Pairs": []interface{}{[]interface{}{"y", "z"}}},
+	}
+	for _, batch := range batches {
+		if err := d.Decode(batch, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	want := Batch{Name: "b", IDs: []int{1, 2, 3}, Raw: []byte("abc"), Pairs: [][]string{{"x"}, {"y", "z"}}}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("expected %+v, got %+v", want, dst)
+	}
+
+	t.Run("failed batch leaves slice unchanged", func(t *testing.T) {
+		dst := Batch{IDs: []int{1}}
+		if err := d.Decode(map[string]interface{}{"IDs": []interface{}{2, "x"}}, &dst); err == nil {
+			t.Fatal("expected error")
+		}
+		if !reflect.DeepEqual(dst.IDs, []int{1}) {
+			t.Errorf("unexpected result: %v", dst.IDs)
+		}
+	})
+
+	t.Run("replaces by default", func(t *testing.T) {
+		dst := Batch{IDs: []int{1}}
+		if err := NewDecoder().Decode(map[string]interface{}{"IDs": []interface{}{2}}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(dst.IDs, []int{2}) {
+			t.Errorf("unexpected result: %v", dst.IDs)
+		}
+	})
+}
+
+func TestWithWrapErrors(t *testing.T) {
+	type Config struct {
+		Port int
+	}
+	d := NewDecoder(WithWrapErrors())
+	src := map[string]interface{}{"Port": "x"}
+
+	var dst Config
+	err := d.Decode(src, &dst)
+	if err == nil || !strings.HasPrefix(err.Error(), "Decode into *main.Config: ") {
+		t.Fatalf("expected wrapped error, got %v", err)
+	}
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.Field != "Port" {
+		t.Errorf("expected wrapped *DecodeError for Port, got %v", err)
+	}
+
+	calls := map[string]error{
+		"MergeInto": d.MergeInto(&dst, src),
+		"Patch":     d.Patch(src, &dst),
+		"JSON":      d.DecodeJSON([]byte(`{"Port": "x"}`), &dst),
+		"syntax":    d.DecodeJSON([]byte(`{`), &dst),
+		"Table":     d.DecodeTable([]string{"Port"}, [][]interface{}{{"x"}}, &[]Config{}),
+	}
+	for name, err := range calls {
+		if err == nil || strings.Count(err.Error(), "Decode into") != 1 {
+			t.Errorf("%s: expected error wrapped once, got %v", name, err)
+		}
+	}
+
+	if err := NewDecoder().Decode(src, &dst); err == nil || strings.Contains(err.Error(), "Decode into") {
+		t.Errorf("expected unwrapped error by default, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// setInt stores v in the signed integer dst. Values outside the range of dst are rejected
+// unless AllowTruncate is set, in which case they wrap as in a Go conversion.
+func (d *Decoder) setInt(dst reflect.Value, v int64) error {
+	if !d.opts.AllowTruncate && dst.OverflowInt(v) {
+		return fmt.Errorf("value %d overflows %s", v, dst.Type())
+	}
+	dst.SetInt(v)
+	return nil
+}
+
+// setUint is setInt for unsigned integer destinations.
+func (d *Decoder) setUint(dst reflect.Value, v uint64) error {
+	if !d.opts.AllowTruncate && dst.OverflowUint(v) {
+		return fmt.Errorf("value %d overflows %s", v, dst.Type())
+	}
+	dst.SetUint(v)
+	return nil
+}
+
+// setIntFromFloat stores f in the signed integer dst. Unless AllowTruncate is set, f must be a
+// whole number within the range of dst.
+func (d *Decoder) setIntFromFloat(dst reflect.Value, f float64) error {
+	if d.opts.AllowTruncate {
+		dst.SetInt(int64(f))
+		return nil
+	}
+	if math.Trunc(f) != f {
+		return fmt.Errorf("value %v has a fractional part and cannot be stored in %s", f, dst.Type())
+	}
+	// float64(math.MaxInt64) rounds up to 2^63, which no longer fits.
+	if f < math.MinInt64 || f >= math.MaxInt64 || dst.OverflowInt(int64(f)) {
+		return fmt.Errorf("value %v overflows %s", f, dst.Type())
+	}
+	dst.SetInt(int64(f))
+	return nil
+}
+
+// setUintFromFloat is setIntFromFloat for unsigned integer destinations.
+func (d *Decoder) setUintFromFloat(dst reflect.Value, f float64) error {
+	if d.opts.AllowTruncate {
+		dst.SetUint(uint64(f))
+		return nil
+	}
+	if math.Trunc(f) != f {
+		return fmt.Errorf("value %v has a fractional part and cannot be stored in %s", f, dst.Type())
+	}
+	if f < 0 || f >= math.MaxUint64 || dst.OverflowUint(uint64(f)) {
+		return fmt.Errorf("value %v overflows %s", f, dst.Type())
+	}
+	dst.SetUint(uint64(f))
+	return nil
+}
+
+// setFloat stores f in the float dst, rejecting finite values beyond the range of a float32
+// destination unless AllowTruncate is set.
+func (d *Decoder) setFloat(dst reflect.Value, f float64) error {
+	if !d.opts.AllowTruncate && dst.OverflowFloat(f) {
+		return fmt.Errorf("value %v overflows %s", f, dst.Type())
+	}
+	dst.SetFloat(f)
+	return nil
+}
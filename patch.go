@@ -0,0 +1,19 @@
+package main
+
+// Patch merges patch into the struct pointed to by target with JSON merge patch (RFC 7396)
+// semantics: only the fields whose keys are present in patch are updated, nested objects are
+// merged into the existing nested structs and maps, and a nil value resets a field to its zero
+// value or removes a map entry. Missing keys are never an error, so `required` tags are not
+// checked, and neither defaults nor WithZeroMissing and WithZeroBeforeDecode are applied.
+func (d *Decoder) Patch(patch map[string]interface{}, target interface{}) error {
+	s := d.session()
+	defer d.release(s)
+	s.patching = true
+	return d.wrapError(target, s.decode(patch, target))
+}
+
+// merging reports whether fields absent from the source keep their current value, as with
+// WithMergeMode or while patching.
+func (d *Decoder) merging() bool {
+	return d.opts.MergeMode || d.patching
+}
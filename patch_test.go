@@ -0,0 +1,66 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPatch(t *testing.T) {
+	type Address struct {
+		City string
+		Zip  string
+	}
+	type User struct {
+		Name    string `gomap:",required"`
+		Age     int    `gomap:",default=18"`
+		Email   *string
+		Address Address
+		Labels  map[string]string
+	}
+
+	email := "john@example.com"
+	user := User{
+		Name:    "john",
+		Age:     30,
+		Email:   &email,
+		Address: Address{City: "Berlin", Zip: "10115"},
+		Labels:  map[string]string{"team": "core", "role": "dev"},
+	}
+
+	patch := map[string]interface{}{
+		"Age":     31,
+		"Email":   nil,
+		"Address": map[string]interface{}{"City": "Paris"},
+		"Labels":  map[string]interface{}{"role": nil, "level": "senior"},
+	}
+	if err := NewDecoder(WithStrictMode(), WithZeroMissing()).Patch(patch, &user); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := User{
+		Name:    "john",
+		Age:     31,
+		Address: Address{City: "Paris", Zip: "10115"},
+		Labels:  map[string]string{"team": "core", "level": "senior"},
+	}
+	if !reflect.DeepEqual(user, want) {
+		t.Errorf("expected %+v, got %+v", want, user)
+	}
+
+	t.Run("unknown key in strict mode", func(t *testing.T) {
+		err := NewDecoder(WithStrictMode()).Patch(map[string]interface{}{"Nmae": "x"}, &user)
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("decoder state is restored", func(t *testing.T) {
+		d := NewDecoder()
+		if err := d.Patch(map[string]interface{}{}, &User{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := d.Decode(map[string]interface{}{}, &User{}); err == nil {
+			t.Error("expected required field error after Patch")
+		}
+	})
+}
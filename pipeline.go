@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TransformPipeline is a step of the source transformation pipeline configured with WithPipeline.
+type TransformPipeline interface {
+	Transform(map[string]interface{}) (map[string]interface{}, error)
+}
+
+// TransformFunc adapts a function to the TransformPipeline interface.
+type TransformFunc func(map[string]interface{}) (map[string]interface{}, error)
+
+// Transform calls f(m).
+func (f TransformFunc) Transform(m map[string]interface{}) (map[string]interface{}, error) {
+	return f(m)
+}
+
+// KeyRenameTransformer renames the keys listed in rename (old name to new name).
+// Keys not listed are kept unchanged.
+func KeyRenameTransformer(rename map[string]string) TransformPipeline {
+	return TransformFunc(func(m map[string]interface{}) (map[string]interface{}, error) {
+		out := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			if to, ok := rename[k]; ok {
+				k = to
+			}
+			out[k] = v
+		}
+		return out, nil
+	})
+}
+
+// KeyFilterTransformer drops every key that is not listed in allow.
+func KeyFilterTransformer(allow []string) TransformPipeline {
+	return TransformFunc(func(m map[string]interface{}) (map[string]interface{}, error) {
+		out := make(map[string]interface{}, len(allow))
+		for k, v := range m {
+			if slices.Contains(allow, k) {
+				out[k] = v
+			}
+		}
+		return out, nil
+	})
+}
+
+// KeyPrefixTransformer removes the prefix strip from the keys that have it, then prepends add
+// to every key. Either prefix may be empty.
+func KeyPrefixTransformer(add, strip string) TransformPipeline {
+	return TransformFunc(func(m map[string]interface{}) (map[string]interface{}, error) {
+		out := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			out[add+strings.TrimPrefix(k, strip)] = v
+		}
+		return out, nil
+	})
+}
+
+// applyPipeline runs m through the transformers in order, stopping at the first error.
+func applyPipeline(transformers []TransformPipeline, m map[string]interface{}) (map[string]interface{}, error) {
+	for i, t := range transformers {
+		var err error
+		if m, err = t.Transform(m); err != nil {
+			return nil, fmt.Errorf("transformer %d: %w", i, err)
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBuiltinTransformers(t *testing.T) {
+	src := map[string]interface{}{"APP_HOST": "localhost", "APP_PORT": 80, "OTHER": true}
+
+	tests := []struct {
+		name        string
+		transformer TransformPipeline
+		want        map[string]interface{}
+	}{
+		{
+			"rename",
+			KeyRenameTransformer(map[string]string{"APP_HOST": "Host"}),
+			map[string]interface{}{"Host": "localhost", "APP_PORT": 80, "OTHER": true},
+		},
+		{
+			"filter",
+			KeyFilterTransformer([]string{"APP_PORT", "MISSING"}),
+			map[string]interface{}{"APP_PORT": 80},
+		},
+		{
+			"strip prefix",
+			KeyPrefixTransformer("", "APP_"),
+			map[string]interface{}{"HOST": "localhost", "PORT": 80, "OTHER": true},
+		},
+		{
+			"replace prefix",
+			KeyPrefixTransformer("cfg.", "APP_"),
+			map[string]interface{}{"cfg.HOST": "localhost", "cfg.PORT": 80, "cfg.OTHER": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.transformer.Transform(src)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Transform() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if len(src) != 3 || src["APP_HOST"] != "localhost" {
+		t.Errorf("transformers modified their input: %v", src)
+	}
+}
+
+func TestWithPipeline(t *testing.T) {
+	type Config struct {
+		Host string
+		Port int
+	}
+
+	src := map[string]interface{}{"APP_HOST": "localhost", "APP_PORT": 80, "APP_SECRET": "x"}
+	d := NewDecoder(WithPipeline(
+		KeyFilterTransformer([]string{"APP_HOST", "APP_PORT"}),
+		KeyPrefixTransformer("", "APP_"),
+		KeyRenameTransformer(map[string]string{"HOST": "Host", "PORT": "Port"}),
+	))
+
+	var dst Config
+	if err := d.Decode(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst != (Config{Host: "localhost", Port: 80}) {
+		t.Errorf("unexpected result: %+v", dst)
+	}
+
+	t.Run("error stops pipeline", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		called := false
+		d := NewDecoder(WithPipeline(
+			TransformFunc(func(map[string]interface{}) (map[string]interface{}, error) { return nil, errBoom }),
+			TransformFunc(func(m map[string]interface{}) (map[string]interface{}, error) {
+				called = true
+				return m, nil
+			}),
+		))
+		var dst Config
+		if err := d.Decode(src, &dst); !errors.Is(err, errBoom) {
+			t.Errorf("expected transformer error, got %v", err)
+		}
+		if called {
+			t.Error("expected pipeline to stop at the first error")
+		}
+	})
+}
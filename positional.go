@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// exportedFields returns the exported fields of the struct v in declaration order, reading
+// their tags from tagKey.
+func exportedFields(v reflect.Value, tagKey string) []structField {
+	fields := make([]structField, 0, v.NumField())
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fields = append(fields, structField{
+			name:  field.Name,
+			value: v.Field(i),
+			tag:   parseFieldTag(field.Tag.Get(tagKey)),
+		})
+	}
+	return fields
+}
+
+// DecodeByIndex decodes a map keyed by position into the struct pointed to by out: key 0 is
+// assigned to the first exported field, key 1 to the second and so on. Keys may be sparse;
+// a key outside the range of exported fields is an error. Keys are decoded in ascending order,
+// and keys of fields that are not decodable, as tagged decode_ignore, outside WithGroups or
+// outside the version of WithAPIVersion, are skipped.
+func (d *Decoder) DecodeByIndex(data map[int]interface{}, out interface{}) error {
+	return d.wrapError(out, d.decodeByIndex(data, out))
+}
+
+// decodeByIndex is DecodeByIndex without WithWrapErrors.
+func (d *Decoder) decodeByIndex(data map[int]interface{}, out interface{}) error {
+	outVal := reflect.ValueOf(out)
+	if outVal.Kind() != reflect.Pointer || outVal.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("out must be a pointer to a struct, got %T", out)
+	}
+
+	fields := exportedFields(outVal.Elem(), d.opts.tagKey())
+	keys := slices.Sorted(maps.Keys(data))
+	for _, key := range keys {
+		if key < 0 || key >= len(fields) {
+			return fmt.Errorf("index %d out of range for %s with %d exported fields",
+				key, outVal.Elem().Type(), len(fields))
+		}
+	}
+
+	s := d.session()
+	defer d.release(s)
+	for _, key := range keys {
+		if !s.decodablePositional(fields[key]) {
+			continue
+		}
+		if err := s.decodeField(strconv.Itoa(key), reflect.ValueOf(data[key]), fields[key]); err != nil {
+			return fieldError(fields[key], err)
+		}
+	}
+	return nil
+}
+
+// decodablePositional is decodable for fields addressed by position rather than by name,
+// which also leaves out versioned fields outside the version selected with WithAPIVersion.
+func (d *Decoder) decodablePositional(field structField) bool {
+	if !d.decodable(field) {
+		return false
+	}
+	r, ok, err := field.tag.versions()
+	return err == nil && (!ok || d.opts.APIVersion == nil || r.contains(*d.opts.APIVersion))
+}
+
+// positionalStruct reports whether out, a struct or a pointer to one, receives slice and array
+// sources by position: with WithPositionalSlice, or when one of its fields is tagged `pos`.
+func (d *Decoder) positionalStruct(out reflect.Value) bool {
+	t := indirectType(out.Type())
+	return t.Kind() == reflect.Struct && (d.opts.PositionalSlice || d.structMeta(t).positional)
+}
+
+// assignPositional decodes the elements of the slice or array data into the exported fields of
+// the struct out in declaration order. Elements beyond the last field are ignored, and fields
+// beyond the last element are left unchanged. A nil pointer destination is allocated first.
+func (d *Decoder) assignPositional(data reflect.Value, out reflect.Value) error {
+	for out.Kind() == reflect.Pointer {
+		if out.IsNil() {
+			if err := d.allocatePtr(out); err != nil {
+				return err
+			}
+		}
+		out = out.Elem()
+	}
+
+	fields := exportedFields(out, d.opts.tagKey())
+	for i := range min(data.Len(), len(fields)) {
+		if !d.decodablePositional(fields[i]) {
+			continue
+		}
+		if err := d.decodeField(strconv.Itoa(i), data.Index(i), fields[i]); err != nil {
+			return fmt.Errorf("element %d: %w", i, fieldError(fields[i], err))
+		}
+	}
+	return nil
+}
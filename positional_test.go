@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeByIndex(t *testing.T) {
+	type Row struct {
+		ID     int
+		hidden string
+		Name   string
+		Score  float64
+	}
+
+	t.Run("full map", func(t *testing.T) {
+		var dst Row
+		err := NewDecoder().DecodeByIndex(map[int]interface{}{0: 1, 1: "john", 2: 9.5}, &dst)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst != (Row{ID: 1, Name: "john", Score: 9.5}) {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("sparse map", func(t *testing.T) {
+		dst := Row{Name: "keep"}
+		if err := NewDecoder().DecodeByIndex(map[int]interface{}{2: 1}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst != (Row{Name: "keep", Score: 1}) {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		var dst Row
+		err := NewDecoder().DecodeByIndex(map[int]interface{}{3: 1}, &dst)
+		if err == nil || !strings.Contains(err.Error(), "3") || !strings.Contains(err.Error(), "Row") {
+			t.Errorf("expected out of range error naming key and type, got %v", err)
+		}
+		err = NewDecoder().DecodeByIndex(map[int]interface{}{-1: 1}, &dst)
+		if err == nil {
+			t.Error("expected error for negative index")
+		}
+	})
+
+	t.Run("fields that are not decodable", func(t *testing.T) {
+		type Tagged struct {
+			ID       int
+			Internal string `gomap:",decode_ignore"`
+			Legacy   string `gomap:",version=1"`
+			Admin    string `gomap:",group=admin"`
+		}
+		var dst Tagged
+		src := map[int]interface{}{0: 1, 1: "x", 2: "y", 3: "z"}
+		d := NewDecoder(WithAPIVersion(2), WithGroups("public"))
+		if err := d.DecodeByIndex(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst != (Tagged{ID: 1}) {
+			t.Errorf("expected only ID to be set, got %+v", dst)
+		}
+	})
+
+	t.Run("errors in key order", func(t *testing.T) {
+		src := map[int]interface{}{0: "a", 2: "b", 3: "c", 4: 5}
+		for range 20 {
+			var dst Row
+			err := NewDecoder().DecodeByIndex(src, &dst)
+			if err == nil || !strings.Contains(err.Error(), "index 3") {
+				t.Fatalf("expected the first out of range key to be reported, got %v", err)
+			}
+			delete(src, 3)
+			delete(src, 4)
+			err = NewDecoder().DecodeByIndex(src, &dst)
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) || decodeErr.Field != "ID" {
+				t.Fatalf("expected the error of the first key, got %v", err)
+			}
+			src[3], src[4] = "c", 5
+		}
+	})
+
+	t.Run("non-struct out", func(t *testing.T) {
+		var dst int
+		if err := NewDecoder().DecodeByIndex(map[int]interface{}{0: 1}, &dst); err == nil {
+			t.Error("expected error for non-struct out")
+		}
+	})
+}
+
+func TestPositionalSlice(t *testing.T) {
+	type Point struct {
+		X int `gomap:",pos"`
+		Y int
+		Z int
+	}
+	type Row struct {
+		Name   string
+		Age    int
+		hidden string
+		Active bool
+	}
+
+	t.Run("pos tag", func(t *testing.T) {
+		var dst Point
+		if err := i2s([]interface{}{1, 2, 3}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst != (Point{1, 2, 3}) {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("extra elements are ignored and missing ones leave zero values", func(t *testing.T) {
+		var long, short Point
+		if err := i2s([]int{4, 5, 6, 7}, &long); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := i2s([]interface{}{8}, &short); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if long != (Point{4, 5, 6}) || short != (Point{X: 8}) {
+			t.Errorf("unexpected results: %+v %+v", long, short)
+		}
+	})
+
+	t.Run("nested in slices and pointers", func(t *testing.T) {
+		type Path struct {
+			Points []Point
+			Origin *Point
+		}
+		var dst Path
+		src := map[string]interface{}{
+			"Points": []interface{}{[]interface{}{1, 2}, [3]int{3, 4, 5}},
+			"Origin": []interface{}{0, 0, 1},
+		}
+		if err := i2s(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(dst.Points) != 2 || dst.Points[0] != (Point{1, 2, 0}) || dst.Points[1] != (Point{3, 4, 5}) {
+			t.Errorf("unexpected Points: %+v", dst.Points)
+		}
+		if dst.Origin == nil || *dst.Origin != (Point{Z: 1}) {
+			t.Errorf("unexpected Origin: %+v", dst.Origin)
+		}
+	})
+
+	t.Run("option skips unexported fields", func(t *testing.T) {
+		var dst Row
+		d := NewDecoder(WithPositionalSlice(), WithWeakDecode())
+		if err := d.Decode([]interface{}{"ann", "31", "true"}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Name != "ann" || dst.Age != 31 || dst.hidden != "" || !dst.Active {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("disabled without tag or option", func(t *testing.T) {
+		var dst Row
+		if err := i2s([]interface{}{"ann"}, &dst); err == nil {
+			t.Error("expected error decoding a slice into a struct")
+		}
+	})
+
+	t.Run("element error", func(t *testing.T) {
+		var dst Point
+		err := i2s([]interface{}{1, "two"}, &dst)
+		if err == nil || !strings.Contains(err.Error(), "element 1") {
+			t.Errorf("expected error for element 1, got %v", err)
+		}
+	})
+}
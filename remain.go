@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// splitRemain moves the entries of the string-keyed map data that match no field of the
+// struct out into its field tagged `remain`, which must be a map[string]interface{}, and
+// returns the other entries. The captured keys are thus neither unknown in strict mode nor
+// reported to WithWarningHandler. As for other map fields, the keys are added to the map the field
+// already holds, which is allocated if needed.
+func (d *Decoder) splitRemain(data reflect.Value, out reflect.Value, meta *structMeta) (reflect.Value, error) {
+	if len(meta.remain) > 1 {
+		names := make([]string, len(meta.remain))
+		for i, fm := range meta.remain {
+			names[i] = fm.name
+		}
+		return reflect.Value{}, fmt.Errorf("fields %s are all tagged remain, at most one is allowed",
+			strings.Join(names, ", "))
+	}
+
+	field := meta.remain[0].field(out)
+	if t := field.typ(); t != reflect.TypeFor[map[string]interface{}]() {
+		return reflect.Value{}, fmt.Errorf("field %s is tagged remain and must be a map[string]interface{}, got %s",
+			field.name, t)
+	}
+
+	fieldsMap, err := d.mapStructFieldsByName(out)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	known := reflect.MakeMapWithSize(data.Type(), data.Len())
+	iter := data.MapRange()
+	for iter.Next() {
+		if _, ok := d.lookupField(fieldsMap, iter.Key().String()); ok {
+			known.SetMapIndex(iter.Key(), iter.Value())
+			continue
+		}
+		if field.allocate(); !field.value.CanSet() {
+			return reflect.Value{}, unsettableField(field)
+		}
+		if field.value.IsNil() {
+			field.value.Set(reflect.ValueOf(make(map[string]interface{})))
+		}
+		field.value.SetMapIndex(iter.Key().Convert(reflect.TypeFor[string]()), iter.Value())
+	}
+	return known, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRemainTag(t *testing.T) {
+	type Event struct {
+		ID    int
+		Name  string
+		Other map[string]interface{} `gomap:",remain"`
+	}
+
+	src := map[string]interface{}{"ID": 1, "Name": "deploy", "region": "eu", "retries": 3}
+	var dst Event
+	if err := NewDecoder(WithStrictMode()).Decode(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Event{ID: 1, Name: "deploy", Other: map[string]interface{}{"region": "eu", "retries": 3}}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("expected %+v, got %+v", want, dst)
+	}
+
+	encoded, err := s2i(dst)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(encoded, src) {
+		t.Errorf("expected remaining keys to be encoded back, got %v", encoded)
+	}
+
+	t.Run("no leftover keys", func(t *testing.T) {
+		var dst Event
+		if err := i2s(map[string]interface{}{"ID": 2}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Other != nil {
+			t.Errorf("expected remain field to stay nil, got %v", dst.Other)
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		var dst Event
+		err := NewDecoder().MergeInto(&dst, map[string]interface{}{"a": 1}, map[string]interface{}{"b": 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(dst.Other, map[string]interface{}{"a": 1, "b": 2}) {
+			t.Errorf("expected remaining keys of every source, got %v", dst.Other)
+		}
+	})
+
+	t.Run("several remain fields", func(t *testing.T) {
+		type Twice struct {
+			A map[string]interface{} `gomap:",remain"`
+			B map[string]interface{} `gomap:",remain"`
+		}
+		var dst Twice
+		err := i2s(map[string]interface{}{"x": 1}, &dst)
+		if err == nil || !strings.Contains(err.Error(), "at most one") {
+			t.Errorf("expected error for several remain fields, got %v", err)
+		}
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		type Bad struct {
+			Rest map[string]string `gomap:",remain"`
+		}
+		var dst Bad
+		err := i2s(map[string]interface{}{"x": "y"}, &dst)
+		if err == nil || !strings.Contains(err.Error(), "must be a map[string]interface{}") {
+			t.Errorf("expected type error, got %v", err)
+		}
+	})
+}
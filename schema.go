@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// schemaDialect is the JSON Schema draft Schema output declares.
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema returns a JSON Schema describing the maps Decode accepts for the struct type of v,
+// which may be a struct, a pointer to one or a nil pointer of that type, using the default
+// options.
+func Schema(v interface{}) (map[string]interface{}, error) {
+	return NewDecoder().Schema(v)
+}
+
+// Schema returns a JSON Schema (draft 2020-12) describing the maps d decodes into the struct
+// type of v. Every decodable field becomes an entry of "properties" under its effective name,
+// fields tagged `required` are listed in "required" and `default` tag values are reported as
+// "default". Named nested structs are described once in "$defs" and referenced with "$ref",
+// which also covers recursive types; anonymous structs are described inline. With
+// WithStrictMode, objects disallow additional properties.
+func (d *Decoder) Schema(v interface{}) (map[string]interface{}, error) {
+	t := reflect.TypeOf(v)
+	if t == nil || indirectType(t).Kind() != reflect.Struct {
+		return nil, fmt.Errorf("v must be a struct or a pointer to a struct, got %T", v)
+	}
+
+	b := schemaBuilder{d: d, defs: make(map[string]interface{}), names: make(map[reflect.Type]string)}
+	root, err := b.object(indirectType(t))
+	if err != nil {
+		return nil, err
+	}
+	root["$schema"] = schemaDialect
+	if len(b.defs) > 0 {
+		root["$defs"] = b.defs
+	}
+	return root, nil
+}
+
+// schemaBuilder accumulates the "$defs" of a schema while its types are described.
+type schemaBuilder struct {
+	d     *Decoder
+	defs  map[string]interface{}
+	names map[reflect.Type]string
+}
+
+// object describes the struct type t as a JSON Schema object.
+func (b *schemaBuilder) object(t reflect.Type) (map[string]interface{}, error) {
+	fieldsMap, err := b.d.mapStructFieldsByName(reflect.New(t).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	properties := make(map[string]interface{}, len(fieldsMap))
+	var required []string
+	for name, field := range fieldsMap {
+		if !b.d.decodable(field) {
+			continue
+		}
+		prop, err := b.describe(field.typ())
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", field.name, err)
+		}
+		if raw, ok := field.tag.value("default"); ok {
+			value, err := b.d.parseDefault(field.typ(), raw)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", field.name, err)
+			}
+			prop["default"] = dereferencePtr(value).Interface()
+		}
+		properties[name] = prop
+		if field.tag.has("required") {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		slices.Sort(required)
+		schema["required"] = required
+	}
+	if b.d.opts.Strict && len(b.d.structMeta(t).remain) == 0 {
+		// a field tagged remain accepts any other key.
+		schema["additionalProperties"] = false
+	}
+	return schema, nil
+}
+
+// describe returns the schema of values decoded into the type t.
+func (b *schemaBuilder) describe(t reflect.Type) (map[string]interface{}, error) {
+	t = indirectType(t)
+	switch t {
+	case reflect.TypeFor[time.Time]():
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	case reflect.TypeFor[time.Duration]():
+		return map[string]interface{}{"type": "string", "format": "duration"}, nil
+	case reflect.TypeFor[big.Int]():
+		return map[string]interface{}{"type": []string{"integer", "string"}}, nil
+	case reflect.TypeFor[big.Float]():
+		return map[string]interface{}{"type": []string{"number", "string"}}, nil
+	case reflect.TypeFor[net.IP]():
+		return map[string]interface{}{"type": "string", "format": "ip"}, nil
+	case reflect.TypeFor[url.URL]():
+		return map[string]interface{}{"type": "string", "format": "uri"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string"}, nil
+		}
+		items, err := b.describe(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		values, err := b.describe(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		if isSQLNull(t) {
+			return b.describe(t.Field(0).Type)
+		}
+		return b.ref(t)
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+// ref returns a "$ref" to the definition of the named struct type t, adding the definition
+// to "$defs" on first use. Anonymous structs are described inline.
+func (b *schemaBuilder) ref(t reflect.Type) (map[string]interface{}, error) {
+	if t.Name() == "" {
+		return b.object(t)
+	}
+	if name, ok := b.names[t]; ok {
+		return map[string]interface{}{"$ref": "#/$defs/" + name}, nil
+	}
+
+	name := t.Name()
+	if _, taken := b.defs[name]; taken {
+		name = t.String()
+	}
+	// register the name before describing t so that recursive fields refer back to it.
+	b.names[t] = name
+	b.defs[name] = nil
+	def, err := b.object(t)
+	if err != nil {
+		return nil, err
+	}
+	b.defs[name] = def
+	return map[string]interface{}{"$ref": "#/$defs/" + name}, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaNode struct {
+	Value    int
+	Children []schemaNode
+}
+
+func TestSchema(t *testing.T) {
+	type Address struct {
+		City string `gomap:"city,required"`
+	}
+	type User struct {
+		Name      string `gomap:"name,required"`
+		Age       int    `gomap:",default=18"`
+		Score     *float64
+		Tags      []string
+		Labels    map[string]int
+		Home      Address
+		Work      *Address
+		CreatedAt time.Time
+		Password  string `gomap:",decode_ignore"`
+		Meta      struct{ Source string }
+		Extra     interface{}
+	}
+
+	schema, err := Schema(&User{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	addressRef := map[string]interface{}{"$ref": "#/$defs/Address"}
+	want := map[string]interface{}{
+		"$schema": schemaDialect,
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"name":  map[string]interface{}{"type": "string"},
+			"Age":   map[string]interface{}{"type": "integer", "default": 18},
+			"Score": map[string]interface{}{"type": "number"},
+			"Tags":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			"Labels": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "integer"},
+			},
+			"Home":      addressRef,
+			"Work":      addressRef,
+			"CreatedAt": map[string]interface{}{"type": "string", "format": "date-time"},
+			"Meta": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"Source": map[string]interface{}{"type": "string"}},
+			},
+			"Extra": map[string]interface{}{},
+		},
+		"required": []string{"name"},
+		"$defs": map[string]interface{}{
+			"Address": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"city": map[string]interface{}{"type": "string"}},
+				"required":   []string{"city"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(schema, want) {
+		t.Errorf("expected %v, got %v", want, schema)
+	}
+
+	t.Run("recursive type", func(t *testing.T) {
+		schema, err := Schema(schemaNode{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		children := schema["properties"].(map[string]interface{})["Children"]
+		want := map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/$defs/schemaNode"}}
+		if !reflect.DeepEqual(children, want) {
+			t.Errorf("unexpected schema for Children: %v", children)
+		}
+		if _, err := json.Marshal(schema); err != nil {
+			t.Errorf("schema is not JSON encodable: %v", err)
+		}
+	})
+
+	t.Run("strict mode", func(t *testing.T) {
+		schema, err := NewDecoder(WithStrictMode()).Schema(Address{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if schema["additionalProperties"] != false {
+			t.Errorf("expected additionalProperties false, got %v", schema["additionalProperties"])
+		}
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		if _, err := Schema(42); err == nil {
+			t.Error("expected error")
+		}
+		if _, err := Schema(nil); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("unsupported field", func(t *testing.T) {
+		type Bad struct{ C chan int }
+		if _, err := Schema(Bad{}); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// fieldKey identifies a field by the address and type of the struct value it was looked up
+// in, and by its Go name. The type tells a struct apart from its first field, which shares
+// its address. Unlike the field's own address, the key does not change when nil embedded
+// pointers on the way to the field are allocated.
+type fieldKey struct {
+	addr uintptr
+	typ  reflect.Type
+	name string
+}
+
+// key returns the fieldKey of field, or false if its owner is not addressable.
+func (f structField) key() (fieldKey, bool) {
+	if !f.owner.IsValid() || !f.owner.CanAddr() {
+		return fieldKey{}, false
+	}
+	return fieldKey{f.owner.UnsafeAddr(), f.owner.Type(), f.name}, true
+}
+
+// DecodeFields decodes data into the struct pointed to by out like Decode, but only populates
+// the top-level fields listed in include, named by their effective name or Go field name.
+// Other fields are left untouched, as if tagged decode_ignore: they are neither zeroed, nor
+// defaulted nor checked for `required`. Fields of nested structs are decoded as usual when
+// their parent is included. Names matching no field are an error.
+func (d *Decoder) DecodeFields(data interface{}, out interface{}, include []string) error {
+	return d.wrapError(out, d.decodeSelected(data, out, include, true))
+}
+
+// DecodeExcept is the converse of DecodeFields: it decodes every top-level field except those
+// listed in exclude, e.g. to keep clients from overwriting ID or CreatedAt.
+func (d *Decoder) DecodeExcept(data interface{}, out interface{}, exclude []string) error {
+	return d.wrapError(out, d.decodeSelected(data, out, exclude, false))
+}
+
+// decodeSelected decodes data into out, restricting decodable to the top-level fields that are
+// listed in names when include is true, or not listed otherwise.
+func (d *Decoder) decodeSelected(data interface{}, out interface{}, names []string, include bool) error {
+	outVal := reflect.ValueOf(out)
+	if outVal.Kind() != reflect.Pointer || outVal.IsNil() || outVal.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("out must be a non-nil pointer to a struct, got %T", out)
+	}
+	fieldsMap, err := d.mapStructFieldsByName(outVal.Elem())
+	if err != nil {
+		return err
+	}
+
+	listed := make(map[string]bool, len(names))
+	for _, name := range names {
+		listed[name] = false
+	}
+	selected := make(map[fieldKey]bool, len(fieldsMap))
+	for name, field := range fieldsMap {
+		goName := field.name[strings.LastIndex(field.name, ".")+1:]
+		_, byName := listed[name]
+		_, byGoName := listed[goName]
+		if byName {
+			listed[name] = true
+		}
+		if byGoName {
+			listed[goName] = true
+		}
+		if key, ok := field.key(); ok {
+			selected[key] = (byName || byGoName) == include
+		}
+	}
+	for name, found := range listed {
+		if !found {
+			return fmt.Errorf("unknown field %q", name)
+		}
+	}
+
+	s := d.session()
+	defer d.release(s)
+	s.selected = selected
+	return s.decode(data, out)
+}
+
+// isSelected reports whether field may be decoded under the selection made by DecodeFields or
+// DecodeExcept. Fields other than the selected struct's own are always decodable.
+func (d *Decoder) isSelected(field structField) bool {
+	if d.selected == nil {
+		return true
+	}
+	key, ok := field.key()
+	if !ok {
+		return true
+	}
+	decode, ok := d.selected[key]
+	return !ok || decode
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecodeFields(t *testing.T) {
+	type Address struct {
+		ID   int
+		City string
+	}
+	type Base struct {
+		ID        int
+		CreatedAt time.Time
+	}
+	type User struct {
+		Base
+		Name    string `gomap:"full_name,required"`
+		Role    string `gomap:",default=member"`
+		Address Address
+	}
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	src := map[string]interface{}{
+		"ID":        99,
+		"CreatedAt": "2025-01-01T00:00:00Z",
+		"full_name": "jane",
+		"Role":      "admin",
+		"Address":   map[string]interface{}{"ID": 7, "City": "Paris"},
+	}
+
+	t.Run("DecodeExcept", func(t *testing.T) {
+		user := User{Base: Base{ID: 1, CreatedAt: created}, Name: "john"}
+		if err := NewDecoder(WithZeroMissing()).DecodeExcept(src, &user, []string{"ID", "CreatedAt"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := User{
+			Base:    Base{ID: 1, CreatedAt: created},
+			Name:    "jane",
+			Role:    "admin",
+			Address: Address{ID: 7, City: "Paris"},
+		}
+		if user != want {
+			t.Errorf("expected %+v, got %+v", want, user)
+		}
+	})
+
+	t.Run("DecodeFields", func(t *testing.T) {
+		user := User{Base: Base{ID: 1}, Role: "owner"}
+		if err := NewDecoder(WithStrictMode()).DecodeFields(src, &user, []string{"Name", "Address"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := User{Base: Base{ID: 1}, Name: "jane", Role: "owner", Address: Address{ID: 7, City: "Paris"}}
+		if user != want {
+			t.Errorf("expected %+v, got %+v", want, user)
+		}
+	})
+
+	t.Run("unselected required field", func(t *testing.T) {
+		var user User
+		if err := NewDecoder().DecodeFields(map[string]interface{}{"Role": "x"}, &user, []string{"Role"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if user.Role != "x" {
+			t.Errorf("unexpected result: %+v", user)
+		}
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		var user User
+		if err := NewDecoder().DecodeExcept(src, &user, []string{"Nmae"}); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("decoder is reusable", func(t *testing.T) {
+		d := NewDecoder()
+		var user User
+		if err := d.DecodeFields(src, &user, []string{"Role"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := d.Decode(src, &user); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if user.ID != 99 || user.Name != "jane" {
+			t.Errorf("selection leaked into Decode: %+v", user)
+		}
+	})
+	t.Run("promoted through nil embedded pointer", func(t *testing.T) {
+		type Meta struct {
+			ID      int
+			Created string
+		}
+		type Doc struct {
+			*Meta
+			Title string
+		}
+		var dst Doc
+		src := map[string]interface{}{"ID": 7, "Created": "now", "Title": "t"}
+		if err := NewDecoder().DecodeExcept(src, &dst, []string{"Created"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Meta == nil || dst.ID != 7 || dst.Created != "" || dst.Title != "t" {
+			t.Errorf("expected Created to be left out, got %+v %+v", dst, dst.Meta)
+		}
+	})
+}
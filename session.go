@@ -0,0 +1,52 @@
+package main
+
+import (
+	"maps"
+	"time"
+)
+
+// session returns a Decoder sharing the configuration of d, with its own per-call state, on
+// which a single call runs. Sessions are pooled; each must be handed back to release.
+func (d *Decoder) session() *Decoder {
+	s, _ := d.sessions.Get().(*Decoder)
+	if s == nil {
+		s = &Decoder{}
+	}
+	s.opts, s.metas = d.opts, d.metas
+	d.mu.RLock()
+	s.typeHooks = d.typeHooks
+	d.mu.RUnlock()
+	return s
+}
+
+// release merges the field sources and timings recorded by the session s into d, clears the
+// per-call state of s and returns it to the pool.
+func (d *Decoder) release(s *Decoder) {
+	if len(s.sources) > 0 || len(s.timings) > 0 {
+		d.mu.Lock()
+		if len(s.sources) > 0 {
+			if d.sources == nil {
+				d.sources = make(map[string]SourceInfo, len(s.sources))
+			}
+			maps.Copy(d.sources, s.sources)
+		}
+		for path, elapsed := range s.timings {
+			if d.timings == nil {
+				d.timings = make(map[string]time.Duration, len(s.timings))
+			}
+			d.timings[path] += elapsed
+		}
+		d.mu.Unlock()
+	}
+
+	clear(s.sources)
+	clear(s.timings)
+	s.sourceIndex = 0
+	s.path = s.path[:0]
+	s.ancestors = s.ancestors[:0]
+	s.patching = false
+	s.selected = nil
+	s.ctx = nil
+	s.typeHooks = nil
+	d.sessions.Put(s)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDecoderConcurrentUse(t *testing.T) {
+	type Address struct {
+		City string
+		Zip  int
+	}
+	type User struct {
+		ID      int
+		Name    string
+		Tags    []string
+		Address *Address
+	}
+
+	d := NewDecoder(WithSourceTracking())
+	const goroutines = 100
+
+	var wg sync.WaitGroup
+	for i := range goroutines {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			src := map[string]interface{}{
+				"ID":      i,
+				"Name":    fmt.Sprint("user", i),
+				"Tags":    []interface{}{"a", "b"},
+				"Address": map[string]interface{}{"City": "Berlin", "Zip": i},
+			}
+			var dst User
+			if err := d.Decode(src, &dst); err != nil {
+				t.Errorf("goroutine %d: unexpected error: %v", i, err)
+				return
+			}
+			want := User{
+				ID:      i,
+				Name:    fmt.Sprint("user", i),
+				Tags:    []string{"a", "b"},
+				Address: &Address{City: "Berlin", Zip: i},
+			}
+			if !reflect.DeepEqual(dst, want) {
+				t.Errorf("goroutine %d: expected %+v, got %+v", i, want, dst)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			// registering hooks and reading results must not race with decoding.
+			d.RegisterTypeHook(reflect.TypeFor[[]byte](), func(data interface{}, dst reflect.Value) error {
+				return nil
+			})
+			_ = d.FieldSources()
+		}()
+	}
+	wg.Wait()
+
+	if sources := d.FieldSources(); len(sources) != 6 {
+		t.Errorf("expected sources for 6 fields, got %v", sources)
+	}
+
+	t.Run("per-call state is isolated", func(t *testing.T) {
+		d := NewDecoder()
+		type Item struct{ Name string }
+		var wg sync.WaitGroup
+		for i := range goroutines {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var dst Item
+				var err error
+				if i%2 == 0 {
+					err = d.Patch(map[string]interface{}{"Name": nil}, &dst)
+				} else {
+					err = d.DecodeFields(map[string]interface{}{"Name": "x"}, &dst, []string{"Name"})
+				}
+				if err != nil {
+					t.Errorf("goroutine %d: unexpected error: %v", i, err)
+				}
+				if i%2 == 1 && dst.Name != "x" {
+					t.Errorf("goroutine %d: unexpected result %+v", i, dst)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("cycle detection per call", func(t *testing.T) {
+		shared := map[string]interface{}{"City": "Paris"}
+		var wg sync.WaitGroup
+		for range goroutines {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var dst User
+				err := d.Decode(map[string]interface{}{"Address": shared}, &dst)
+				if err != nil && strings.Contains(err.Error(), "contains itself") {
+					t.Errorf("concurrent calls reported a cycle: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
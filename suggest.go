@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxSuggestDistance is the largest edit distance at which a field name is still suggested
+// for an unknown key.
+const maxSuggestDistance = 3
+
+// editDistance returns the optimal string alignment distance between a and b: the number of
+// insertions, deletions, substitutions and transpositions of adjacent characters needed to
+// turn one into the other.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	// rows i-2, i-1 and i of the distance matrix.
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(rb)]
+}
+
+// suggestField returns the field name closest to key, ignoring case, or "" if no field is
+// within maxSuggestDistance. Ties are broken alphabetically.
+func suggestField(key string, fields map[string]structField) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best, bestDistance := "", maxSuggestDistance+1
+	for _, name := range names {
+		if dist := editDistance(strings.ToLower(key), strings.ToLower(name)); dist < bestDistance {
+			best, bestDistance = name, dist
+		}
+	}
+	return best
+}
+
+// unknownKeyMessage describes a source key without a matching field, suggesting the
+// closest field name when there is one.
+func unknownKeyMessage(key string, fields map[string]structField) string {
+	if suggestion := suggestField(key, fields); suggestion != "" {
+		return fmt.Sprintf("unknown key '%s', did you mean '%s'?", key, suggestion)
+	}
+	return fmt.Sprintf("unknown key '%s'", key)
+}
+
+// unknownKey reports a source key without a matching field to the warning handler, if any,
+// and appends it to unknown in strict mode.
+func (d *Decoder) unknownKey(key string, fields map[string]structField, unknown *[]string) {
+	if d.opts.Warn != nil {
+		d.opts.Warn(unknownKeyMessage(key, fields))
+	}
+	if d.opts.Strict {
+		*unknown = append(*unknown, key)
+	}
+}
+
+// structResult combines the outcome of decoding a source map into a struct: the keys without
+// a matching field found in strict mode and the field errors collected with WithCollectErrors.
+func (d *Decoder) structResult(errs DecodeErrors, unknown []string, fields map[string]structField) error {
+	if len(unknown) > 0 {
+		err := newUnknownKeysError(unknown, fields)
+		if !d.opts.CollectErrors {
+			return err
+		}
+		errs = append(errs, err)
+	}
+	return errs.orNil()
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"keystring", "keystring", 0},
+		{"keystrng", "keystring", 1},
+		{"keystirng", "keystring", 1},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestUnknownKeySuggestion(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{"one character typo", "KeyStrng", "unknown key 'KeyStrng', did you mean 'KeyString'?"},
+		{"transposed characters", "keystirng", "unknown key 'keystirng', did you mean 'KeyString'?"},
+		{"unrelated key", "Completely", "unknown key 'Completely'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warnings []string
+			d := NewDecoder(WithWarningHandler(func(msg string) { warnings = append(warnings, msg) }))
+
+			var dst Simple
+			if err := d.Decode(map[string]interface{}{tt.key: "x"}, &dst); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(warnings, []string{tt.want}) {
+				t.Errorf("warnings = %v, want [%s]", warnings, tt.want)
+			}
+		})
+	}
+
+	t.Run("reflection path", func(t *testing.T) {
+		var warnings []string
+		d := NewDecoder(WithWarningHandler(func(msg string) { warnings = append(warnings, msg) }))
+		var dst Simple
+		if err := d.Decode(genericMap{"KeyIntt": 1}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(warnings) != 1 || warnings[0] != "unknown key 'KeyIntt', did you mean 'KeyInt'?" {
+			t.Errorf("unexpected warnings: %v", warnings)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// DecodeTable decodes tabular data, such as SQL query results or CSV records, into the slice
+// of structs, or of pointers to structs, pointed to by out. Each row becomes one element, its
+// values assigned to the fields named by the matching column headers as if they were map keys.
+// With nil headers the first row holds the headers and must contain only strings. Rows may be
+// shorter than the headers, leaving the remaining fields unset, but not longer. The `required`
+// and `default` tag options and PreDecoder and PostDecoder do not apply, since rows are never
+// turned into maps.
+func (d *Decoder) DecodeTable(headers []string, rows [][]interface{}, out interface{}) error {
+	return d.wrapError(out, d.decodeTable(headers, rows, out))
+}
+
+// decodeTable is DecodeTable without WithWrapErrors.
+func (d *Decoder) decodeTable(headers []string, rows [][]interface{}, out interface{}) error {
+	outVal := reflect.ValueOf(out)
+	if outVal.Kind() != reflect.Pointer || outVal.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("out must be a pointer to a slice, got %T", out)
+	}
+	sliceVal := outVal.Elem()
+	elemType := sliceVal.Type().Elem()
+	if indirectType(elemType).Kind() != reflect.Struct {
+		return fmt.Errorf("expected slice of structs, got %s", sliceVal.Type())
+	}
+
+	if headers == nil {
+		if len(rows) == 0 {
+			return errors.New("no header row")
+		}
+		var err error
+		if headers, err = headerRow(rows[0]); err != nil {
+			return err
+		}
+		rows = rows[1:]
+	}
+
+	s := d.session()
+	defer d.release(s)
+	result := reflect.MakeSlice(sliceVal.Type(), len(rows), len(rows))
+	for i, row := range rows {
+		if len(row) > len(headers) {
+			return fmt.Errorf("row %d has %d values for %d columns", i, len(row), len(headers))
+		}
+		if err := s.assignRow(headers, row, result.Index(i)); err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+	}
+	sliceVal.Set(result)
+	return nil
+}
+
+// headerRow returns the column names held by row, which must all be strings.
+func headerRow(row []interface{}) ([]string, error) {
+	headers := make([]string, len(row))
+	for i, v := range row {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("header %d: expected string, got %T", i, v)
+		}
+		headers[i] = s
+	}
+	return headers, nil
+}
+
+// assignRow decodes the values of row into the fields of out, a struct or pointer to one,
+// named by the matching headers.
+func (d *Decoder) assignRow(headers []string, row []interface{}, out reflect.Value) error {
+	for out.Kind() == reflect.Pointer {
+		if out.IsNil() {
+			if err := d.allocatePtr(out); err != nil {
+				return err
+			}
+		}
+		out = out.Elem()
+	}
+
+	fieldsMap, err := d.mapStructFieldsByName(out)
+	if err != nil {
+		return err
+	}
+
+	var (
+		errs    DecodeErrors
+		unknown []string
+	)
+	for i, value := range row {
+		field, ok := d.lookupField(fieldsMap, headers[i])
+		if !ok {
+			d.unknownKey(headers[i], fieldsMap, &unknown)
+			continue
+		}
+		if !d.decodable(field) {
+			continue
+		}
+		err = d.decodeField(headers[i], reflect.ValueOf(value), field)
+		if err = d.collectError(&errs, field, err); err != nil {
+			return err
+		}
+	}
+	return d.structResult(errs, unknown, fieldsMap)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeTable(t *testing.T) {
+	type User struct {
+		ID    int    `gomap:"id"`
+		Name  string `gomap:"name"`
+		Admin bool   `gomap:"admin"`
+	}
+
+	t.Run("header row", func(t *testing.T) {
+		rows := [][]interface{}{
+			{"id", "name", "admin"},
+			{1, "ann", true},
+			{2, "bob", false},
+		}
+		var dst []User
+		if err := NewDecoder().DecodeTable(nil, rows, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []User{{1, "ann", true}, {2, "bob", false}}
+		if !reflect.DeepEqual(dst, want) {
+			t.Errorf("got %+v, want %+v", dst, want)
+		}
+	})
+
+	t.Run("explicit headers and pointer elements", func(t *testing.T) {
+		rows := [][]interface{}{
+			{"carol", 3},
+			{"dave"},
+		}
+		var dst []*User
+		if err := NewDecoder().DecodeTable([]string{"name", "id"}, rows, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(dst) != 2 || *dst[0] != (User{ID: 3, Name: "carol"}) || *dst[1] != (User{Name: "dave"}) {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("options apply", func(t *testing.T) {
+		rows := [][]interface{}{{"id", "nmae"}, {"7", "eve"}}
+		var dst []User
+		err := NewDecoder(WithWeakDecode(), WithStrictMode()).DecodeTable(nil, rows, &dst)
+		var unknown *UnknownKeysError
+		if !errors.As(err, &unknown) || unknown.Suggestions["nmae"] != "name" {
+			t.Fatalf("expected unknown column with a suggestion, got %v", err)
+		}
+	})
+
+	invalid := []struct {
+		name    string
+		headers []string
+		rows    [][]interface{}
+		msg     string
+	}{
+		{"no header row", nil, nil, "no header row"},
+		{"non-string header", nil, [][]interface{}{{"id", 2}}, "header 1"},
+		{"row too long", []string{"id"}, [][]interface{}{{1, "x"}}, "row 0 has 2 values"},
+		{"bad value", []string{"id"}, [][]interface{}{{1}, {"x"}}, "row 1"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst []User
+			err := NewDecoder().DecodeTable(tt.headers, tt.rows, &dst)
+			if err == nil || !strings.Contains(err.Error(), tt.msg) {
+				t.Errorf("expected error containing %q, got %v", tt.msg, err)
+			}
+		})
+	}
+
+	t.Run("out must be a slice of structs", func(t *testing.T) {
+		var ints []int
+		if err := NewDecoder().DecodeTable([]string{"a"}, nil, &ints); err == nil {
+			t.Error("expected error for []int")
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// defaultTagKey is the struct tag key read unless another one is set with WithTagKey.
+const defaultTagKey = "gomap"
+
+// fieldTag holds the parsed contents of a `gomap:"name,opt,key=value"` struct tag.
+type fieldTag struct {
+	name    string
+	options map[string]string
+}
+
+// parseFieldTag splits a raw tag value into its name and comma-separated options.
+// Options without a value (e.g. "omitempty") are stored with an empty value.
+func parseFieldTag(raw string) fieldTag {
+	if raw == "" {
+		return fieldTag{}
+	}
+
+	parts := strings.Split(raw, ",")
+	tag := fieldTag{name: parts[0]}
+
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		if tag.options == nil {
+			tag.options = make(map[string]string)
+		}
+		key, value, _ := strings.Cut(part, "=")
+		tag.options[key] = value
+	}
+
+	return tag
+}
+
+// has reports whether the tag contains the given option.
+func (t fieldTag) has(opt string) bool {
+	_, ok := t.options[opt]
+	return ok
+}
+
+// value returns the value of a `key=value` option and whether it was present.
+func (t fieldTag) value(opt string) (string, bool) {
+	v, ok := t.options[opt]
+	return v, ok
+}
+
+// ignored reports whether the field is excluded in both directions with `gomap:"-"`.
+func (t fieldTag) ignored() bool {
+	return t.name == "-" && len(t.options) == 0
+}
+
+// skipDecode reports whether the field must not be populated from source data.
+func (t fieldTag) skipDecode() bool {
+	return t.ignored() || t.has("decode_ignore")
+}
+
+// skipEncode reports whether the field must be left out of encoded output.
+func (t fieldTag) skipEncode() bool {
+	return t.ignored() || t.has("encode_ignore")
+}
+
+// versionRange is an inclusive range of API versions a field applies to.
+type versionRange struct {
+	min, max int
+}
+
+// contains reports whether v lies within the range.
+func (r versionRange) contains(v int) bool {
+	return v >= r.min && v <= r.max
+}
+
+// versions parses the `version` option: "N" for a single version, "N-M" for an inclusive
+// range and "N+" for N and every later version. It reports false if the option is absent.
+func (t fieldTag) versions() (versionRange, bool, error) {
+	raw, ok := t.value("version")
+	if !ok {
+		return versionRange{}, false, nil
+	}
+
+	var (
+		r   versionRange
+		err error
+	)
+	switch lo, hi, isRange := strings.Cut(raw, "-"); {
+	case strings.HasSuffix(raw, "+"):
+		r.min, err = strconv.Atoi(strings.TrimSuffix(raw, "+"))
+		r.max = math.MaxInt
+	case isRange:
+		if r.min, err = strconv.Atoi(lo); err == nil {
+			r.max, err = strconv.Atoi(hi)
+		}
+	default:
+		r.min, err = strconv.Atoi(raw)
+		r.max = r.min
+	}
+	if err != nil || r.min > r.max {
+		return versionRange{}, true, fmt.Errorf("invalid version %q", raw)
+	}
+	return r, true, nil
+}
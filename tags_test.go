@@ -0,0 +1,397 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseFieldTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		wantName string
+		wantOpts map[string]string
+	}{
+		{"empty", "", "", nil},
+		{"name only", "user_id", "user_id", nil},
+		{"option without name", ",group=public", "", map[string]string{"group": "public"}},
+		{"name and flag", "id,required", "id", map[string]string{"required": ""}},
+		{"skip", "-", "-", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag := parseFieldTag(tt.raw)
+			if tag.name != tt.wantName {
+				t.Errorf("name = %q, want %q", tag.name, tt.wantName)
+			}
+			if len(tag.options) != len(tt.wantOpts) {
+				t.Fatalf("options = %v, want %v", tag.options, tt.wantOpts)
+			}
+			for k, v := range tt.wantOpts {
+				got, ok := tag.value(k)
+				if !ok || got != v {
+					t.Errorf("option %q = %q (present %v), want %q", k, got, ok, v)
+				}
+				if !tag.has(k) {
+					t.Errorf("expected has(%q) to be true", k)
+				}
+			}
+		})
+	}
+}
+
+func TestFieldNameTags(t *testing.T) {
+	type Tagged struct {
+		UserID int    `gomap:"user_id"`
+		Name   string `gomap:"full_name"`
+	}
+	type Skipped struct {
+		Name     string
+		Password string `gomap:"-"`
+	}
+	type Mixed struct {
+		UserID   int `gomap:"user_id"`
+		Name     string
+		Password string `gomap:"-"`
+	}
+
+	src := map[string]interface{}{
+		"user_id":   7,
+		"UserID":    8,
+		"full_name": "John Doe",
+		"Name":      "john",
+		"Password":  "hunter2",
+	}
+
+	tests := []struct {
+		name     string
+		out      interface{}
+		wantKeys []string
+		want     interface{}
+	}{
+		{"tagged", &Tagged{}, []string{"full_name", "user_id"}, &Tagged{UserID: 7, Name: "John Doe"}},
+		{"skipped", &Skipped{}, []string{"Name"}, &Skipped{Name: "john"}},
+		{"mixed", &Mixed{}, []string{"Name", "user_id"}, &Mixed{UserID: 7, Name: "john"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := NewDecoder().mapStructFieldsByName(reflect.ValueOf(tt.out))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			keys := make([]string, 0, len(fields))
+			for key := range fields {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("keys = %v, want %v", keys, tt.wantKeys)
+			}
+
+			if err := i2s(src, tt.out); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.out, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, tt.out)
+			}
+		})
+	}
+}
+
+func TestDirectionalIgnoreTags(t *testing.T) {
+	type Account struct {
+		Name      string
+		Password  string `gomap:",encode_ignore"`
+		CreatedAt string `gomap:",decode_ignore"`
+		Internal  string `gomap:",decode_ignore,encode_ignore"`
+		Secret    string `gomap:"-"`
+	}
+
+	src := map[string]interface{}{
+		"Name":      "john",
+		"Password":  "hunter2",
+		"CreatedAt": "now",
+		"Internal":  "x",
+		"Secret":    "y",
+	}
+
+	var dst Account
+	if err := i2s(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Account{Name: "john", Password: "hunter2"}
+	if dst != want {
+		t.Errorf("expected %+v, got %+v", want, dst)
+	}
+
+	tests := []struct {
+		raw        string
+		skipDecode bool
+		skipEncode bool
+	}{
+		{"", false, false},
+		{",decode_ignore", true, false},
+		{",encode_ignore", false, true},
+		{",decode_ignore,encode_ignore", true, true},
+		{"-", true, true},
+	}
+	for _, tt := range tests {
+		tag := parseFieldTag(tt.raw)
+		if tag.skipDecode() != tt.skipDecode || tag.skipEncode() != tt.skipEncode {
+			t.Errorf("tag %q: skipDecode=%v skipEncode=%v, want %v %v",
+				tt.raw, tag.skipDecode(), tag.skipEncode(), tt.skipDecode, tt.skipEncode)
+		}
+	}
+}
+
+func TestVersionTags(t *testing.T) {
+	type User struct {
+		NameV1 string `gomap:"name,version=1-2"`
+		NameV3 string `gomap:"name,version=3+"`
+		ID     int
+	}
+
+	src := map[string]interface{}{"name": "john", "ID": 1}
+
+	t.Run("version 3", func(t *testing.T) {
+		var dst User
+		if err := NewDecoder(WithAPIVersion(3)).Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.NameV3 != "john" || dst.NameV1 != "" || dst.ID != 1 {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("version 1", func(t *testing.T) {
+		var dst User
+		if err := NewDecoder(WithAPIVersion(1)).Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.NameV1 != "john" || dst.NameV3 != "" {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("version 0 selects none", func(t *testing.T) {
+		var dst User
+		if err := NewDecoder(WithAPIVersion(0)).Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.NameV1 != "" || dst.NameV3 != "" || dst.ID != 1 {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+	})
+
+	t.Run("no version set", func(t *testing.T) {
+		var dst User
+		err := NewDecoder().Decode(src, &dst)
+		var ambiguity *VersionAmbiguityError
+		if !errors.As(err, &ambiguity) {
+			t.Fatalf("expected VersionAmbiguityError, got %v", err)
+		}
+		if ambiguity.Key != "name" || len(ambiguity.Fields) != 2 {
+			t.Errorf("unexpected error: %+v", ambiguity)
+		}
+	})
+
+	t.Run("version ranges", func(t *testing.T) {
+		tests := []struct {
+			raw     string
+			want    versionRange
+			wantErr bool
+		}{
+			{",version=2", versionRange{2, 2}, false},
+			{",version=1-4", versionRange{1, 4}, false},
+			{",version=5+", versionRange{5, math.MaxInt}, false},
+			{",version=4-1", versionRange{}, true},
+			{",version=x", versionRange{}, true},
+		}
+		for _, tt := range tests {
+			r, ok, err := parseFieldTag(tt.raw).versions()
+			if !ok || (err != nil) != tt.wantErr || (!tt.wantErr && r != tt.want) {
+				t.Errorf("%q: got %+v, %v, %v", tt.raw, r, ok, err)
+			}
+		}
+	})
+}
+
+func TestRequiredTag(t *testing.T) {
+	type Signup struct {
+		Name   string `gomap:"name,required"`
+		Email  string `gomap:"email,required"`
+		Age    int    `gomap:"age,required"`
+		Invite string `gomap:"invite"`
+	}
+
+	t.Run("all present", func(t *testing.T) {
+		var dst Signup
+		// zero values still satisfy the tag, only absent keys are reported.
+		if err := i2s(map[string]interface{}{"name": "", "email": "a@b.c", "age": 0}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("missing keys", func(t *testing.T) {
+		var dst Signup
+		err := i2s(map[string]interface{}{"email": "a@b.c", "invite": "x"}, &dst)
+		var missing *MissingFieldsError
+		if !errors.As(err, &missing) {
+			t.Fatalf("expected MissingFieldsError, got %v", err)
+		}
+		if !reflect.DeepEqual(missing.Fields, []string{"age", "name"}) {
+			t.Errorf("unexpected missing fields: %v", missing.Fields)
+		}
+		if err.Error() != "missing required fields: 'age', 'name'" {
+			t.Errorf("unexpected message: %q", err.Error())
+		}
+		if dst.Email != "a@b.c" {
+			t.Errorf("expected present fields to be assigned, got %+v", dst)
+		}
+	})
+
+	t.Run("nested struct", func(t *testing.T) {
+		type Form struct {
+			User Signup `gomap:"user"`
+		}
+		var dst Form
+		err := i2s(map[string]interface{}{"user": map[string]interface{}{"name": "x", "age": 3}}, &dst)
+		var missing *MissingFieldsError
+		if !errors.As(err, &missing) || !reflect.DeepEqual(missing.Fields, []string{"email"}) {
+			t.Fatalf("expected missing email, got %v", err)
+		}
+	})
+
+	t.Run("collected with field errors", func(t *testing.T) {
+		var dst Signup
+		err := NewDecoder(WithCollectErrors()).Decode(map[string]interface{}{"name": "x", "email": 1}, &dst)
+		var (
+			errs    DecodeErrors
+			missing *MissingFieldsError
+		)
+		if !errors.As(err, &errs) || len(errs) != 2 {
+			t.Fatalf("expected 2 collected errors, got %v", err)
+		}
+		if !errors.As(err, &missing) || !reflect.DeepEqual(missing.Fields, []string{"age"}) {
+			t.Errorf("expected missing age, got %v", err)
+		}
+	})
+}
+
+func TestDefaultTag(t *testing.T) {
+	type Config struct {
+		Host    string        `gomap:"host,default=localhost"`
+		Port    int           `gomap:"port,default=8080"`
+		Level   int8          `gomap:"level,default=-3"`
+		Workers uint16        `gomap:"workers,default=4"`
+		Ratio   float64       `gomap:"ratio,default=0.75"`
+		Debug   bool          `gomap:"debug,default=true"`
+		Timeout time.Duration `gomap:"timeout,default=30s"`
+		Retries *int          `gomap:"retries,default=3"`
+		Name    string        `gomap:"name"`
+	}
+
+	t.Run("absent keys", func(t *testing.T) {
+		var dst Config
+		if err := i2s(map[string]interface{}{"name": "svc"}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Host != "localhost" || dst.Port != 8080 || dst.Level != -3 || dst.Workers != 4 ||
+			dst.Ratio != 0.75 || !dst.Debug || dst.Timeout != 30*time.Second || dst.Name != "svc" {
+			t.Errorf("unexpected result: %+v", dst)
+		}
+		if dst.Retries == nil || *dst.Retries != 3 {
+			t.Errorf("unexpected Retries: %v", dst.Retries)
+		}
+	})
+
+	t.Run("explicit zero overrides default", func(t *testing.T) {
+		var dst Config
+		src := map[string]interface{}{
+			"host": "", "port": 0, "level": 0, "workers": 0, "ratio": 0.0, "debug": false, "timeout": 0, "retries": nil,
+		}
+		if err := i2s(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst != (Config{}) {
+			t.Errorf("expected zero values, got %+v", dst)
+		}
+	})
+
+	t.Run("merge mode keeps existing values", func(t *testing.T) {
+		dst := Config{Port: 9000}
+		if err := NewDecoder(WithMergeMode()).Decode(map[string]interface{}{"name": "svc"}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Port != 9000 || dst.Host != "" {
+			t.Errorf("expected defaults to be skipped, got %+v", dst)
+		}
+	})
+
+	t.Run("invalid default", func(t *testing.T) {
+		type Broken struct {
+			Port int `gomap:"port,default=http"`
+		}
+		var dst Broken
+		if err := i2s(map[string]interface{}{}, &dst); err == nil {
+			t.Error("expected error for invalid default")
+		}
+	})
+}
+
+func TestSquashTag(t *testing.T) {
+	type Core struct {
+		CoreField int
+		Name      string
+	}
+	type Meta struct {
+		Version int
+	}
+	type Wrapper struct {
+		Core Core  `gomap:",squash"`
+		Meta *Meta `gomap:"meta,squash"`
+		Name string
+	}
+
+	src := map[string]interface{}{"CoreField": 1, "Name": "outer", "Version": 3}
+	var dst Wrapper
+	if err := i2s(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Core.CoreField != 1 || dst.Name != "outer" || dst.Core.Name != "" {
+		t.Errorf("expected squashed fields to be decoded at the outer level, got %+v", dst)
+	}
+	if dst.Meta == nil || dst.Meta.Version != 3 {
+		t.Errorf("expected squashed pointer to be allocated, got %+v", dst.Meta)
+	}
+
+	encoded, err := s2i(Wrapper{Core: Core{CoreField: 2}, Name: "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if encoded["CoreField"] != 2 || encoded["Name"] != "x" {
+		t.Errorf("expected squashed fields to be encoded at the outer level, got %v", encoded)
+	}
+	if _, ok := encoded["Core"]; ok {
+		t.Errorf("expected no nested Core key, got %v", encoded)
+	}
+
+	t.Run("unexported", func(t *testing.T) {
+		type Hidden struct {
+			core Core `gomap:",squash"`
+		}
+		var dst Hidden
+		if err := i2s(map[string]interface{}{"CoreField": 1}, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.core.CoreField != 0 {
+			t.Errorf("expected unexported squashed field to be skipped, got %+v", dst)
+		}
+	})
+}
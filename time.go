@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayout returns the layout used to parse strings into time.Time fields.
+func (o *DecoderOptions) timeLayout() string {
+	if o.TimeFormat == "" {
+		return time.RFC3339
+	}
+	return o.TimeFormat
+}
+
+// assignTime decodes data into out when out is a time.Time, or a pointer to one, and data is
+// a string (parsed with the configured layout), an integer (a Unix timestamp in seconds) or a
+// time.Time. It reports false for any other combination.
+func (d *Decoder) assignTime(data reflect.Value, out reflect.Value) (bool, error) {
+	t := indirectType(out.Type())
+	if t != reflect.TypeFor[time.Time]() {
+		return false, nil
+	}
+
+	var (
+		value time.Time
+		err   error
+	)
+	switch data.Kind() {
+	case reflect.String:
+		value, err = time.Parse(d.opts.timeLayout(), data.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = time.Unix(data.Int(), 0)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if data.Uint() > math.MaxInt64 {
+			return true, fmt.Errorf("unix timestamp %d out of range", data.Uint())
+		}
+		value = time.Unix(int64(data.Uint()), 0)
+	case reflect.Struct:
+		if data.Type() != t {
+			return false, nil
+		}
+		value = data.Interface().(time.Time)
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return true, err
+	}
+	return true, d.setIndirect(out, reflect.ValueOf(value))
+}
+
+// assignDuration decodes data into out when out is a time.Duration, or a pointer to one, and
+// data is a string parsed with time.ParseDuration or an integer number of nanoseconds. Strings
+// of digits, such as the json.Number values of DecodeJSONReader, are numbers of nanoseconds
+// too. It reports false for any other combination.
+func (d *Decoder) assignDuration(data reflect.Value, out reflect.Value) (bool, error) {
+	if indirectType(out.Type()) != reflect.TypeFor[time.Duration]() {
+		return false, nil
+	}
+
+	var value time.Duration
+	switch data.Kind() {
+	case reflect.String:
+		s := data.String()
+		if isInteger(s) {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return true, fmt.Errorf("invalid duration %q: %w", s, err)
+			}
+			value = time.Duration(n)
+			break
+		}
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return true, fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		value = parsed
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = time.Duration(data.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if data.Uint() > math.MaxInt64 {
+			return true, fmt.Errorf("value %d overflows time.Duration", data.Uint())
+		}
+		value = time.Duration(data.Uint())
+	default:
+		return false, nil
+	}
+	return true, d.setIndirect(out, reflect.ValueOf(value))
+}
+
+// isInteger reports whether s is a decimal integer: digits with an optional leading sign.
+func isInteger(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// indirectType returns t with every level of pointer removed.
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// setIndirect stores value in out, following and allocating pointers as needed.
+func (d *Decoder) setIndirect(out reflect.Value, value reflect.Value) error {
+	for out.Kind() == reflect.Pointer {
+		if out.IsNil() {
+			if err := d.allocatePtr(out); err != nil {
+				return err
+			}
+		}
+		out = out.Elem()
+	}
+	out.Set(value)
+	return nil
+}
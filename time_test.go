@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeFields(t *testing.T) {
+	type Event struct {
+		At      time.Time
+		Expires *time.Time
+	}
+
+	want := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		opts []Option
+		src  map[string]interface{}
+	}{
+		{"RFC3339 string", nil, map[string]interface{}{"At": "2024-03-01T12:30:00Z", "Expires": "2024-03-01T12:30:00Z"}},
+		{"unix int64", nil, map[string]interface{}{"At": want.Unix(), "Expires": want.Unix()}},
+		{"unix int", nil, map[string]interface{}{"At": int(want.Unix()), "Expires": int(want.Unix())}},
+		{
+			"custom format",
+			[]Option{WithTimeFormat("2006-01-02 15:04")},
+			map[string]interface{}{"At": "2024-03-01 12:30", "Expires": "2024-03-01 12:30"},
+		},
+		{"time value", nil, map[string]interface{}{"At": want, "Expires": want}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst Event
+			if err := NewDecoder(tt.opts...).Decode(tt.src, &dst); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !dst.At.Equal(want) {
+				t.Errorf("At = %v, want %v", dst.At, want)
+			}
+			if dst.Expires == nil || !dst.Expires.Equal(want) {
+				t.Errorf("Expires = %v, want %v", dst.Expires, want)
+			}
+		})
+	}
+
+	t.Run("invalid string", func(t *testing.T) {
+		var dst Event
+		if err := NewDecoder().Decode(map[string]interface{}{"At": "yesterday"}, &dst); err == nil {
+			t.Error("expected parse error")
+		}
+	})
+}
+
+func TestDurationFields(t *testing.T) {
+	type Timeouts struct {
+		Read  time.Duration
+		Write *time.Duration
+	}
+
+	tests := []struct {
+		name string
+		src  interface{}
+		want time.Duration
+	}{
+		{"milliseconds", "500ms", 500 * time.Millisecond},
+		{"compound", "1h30m", 90 * time.Minute},
+		{"zero", "0", 0},
+		{"negative", "-2s", -2 * time.Second},
+		{"int64 nanoseconds", int64(1500), 1500},
+		{"int nanoseconds", 42, 42},
+		{"uint nanoseconds", uint32(7), 7},
+		{"digit string", "1000", 1000},
+		{"json.Number", json.Number("2500"), 2500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst Timeouts
+			if err := NewDecoder().Decode(map[string]interface{}{"Read": tt.src, "Write": tt.src}, &dst); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if dst.Read != tt.want || dst.Write == nil || *dst.Write != tt.want {
+				t.Errorf("expected %v, got %v and %v", tt.want, dst.Read, dst.Write)
+			}
+		})
+	}
+
+	t.Run("DecodeJSONReader", func(t *testing.T) {
+		var dst Timeouts
+		err := NewDecoder().DecodeJSONReader(strings.NewReader(`{"Read": 1000, "Write": "1s"}`), &dst)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.Read != 1000 || dst.Write == nil || *dst.Write != time.Second {
+			t.Errorf("unexpected result: %v, %v", dst.Read, dst.Write)
+		}
+	})
+
+	t.Run("uint overflow", func(t *testing.T) {
+		var dst Timeouts
+		if err := NewDecoder().Decode(map[string]interface{}{"Read": uint64(math.MaxUint64)}, &dst); err == nil {
+			t.Error("expected overflow error")
+		}
+	})
+
+	t.Run("invalid string", func(t *testing.T) {
+		var dst Timeouts
+		err := NewDecoder().Decode(map[string]interface{}{"Read": "soon"}, &dst)
+		if err == nil || !strings.Contains(err.Error(), `invalid duration "soon"`) {
+			t.Errorf("expected descriptive error, got %v", err)
+		}
+	})
+}
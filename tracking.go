@@ -0,0 +1,73 @@
+package main
+
+import (
+	"maps"
+	"strings"
+	"time"
+)
+
+// SourceInfo describes where the value of a decoded field came from.
+type SourceInfo struct {
+	// Index is the position of the source in a MergeInto call, 0 for a plain Decode.
+	Index int
+	// Key is the source map key the value was read from.
+	Key string
+}
+
+// trackSource records the current source for the field at the current path.
+func (d *Decoder) trackSource(key string) {
+	if d.sources == nil {
+		d.sources = make(map[string]SourceInfo)
+	}
+	d.sources[strings.Join(d.path, ".")] = SourceInfo{Index: d.sourceIndex, Key: key}
+}
+
+// FieldSources returns, for every field decoded since the last Reset, the index of the
+// source that last populated it, keyed by dotted field path (e.g. "Config.Host").
+// It is empty unless the decoder was created with WithSourceTracking.
+func (d *Decoder) FieldSources() map[string]int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	out := make(map[string]int, len(d.sources))
+	for path, info := range d.sources {
+		out[path] = info.Index
+	}
+	return out
+}
+
+// FieldSourceInfo is like FieldSources but also reports the source key each field was read from.
+func (d *Decoder) FieldSourceInfo() map[string]SourceInfo {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return maps.Clone(d.sources)
+}
+
+// recordTiming adds elapsed to the decode time of the field at the current path.
+func (d *Decoder) recordTiming(elapsed time.Duration) {
+	if d.timings == nil {
+		d.timings = make(map[string]time.Duration)
+	}
+	d.timings[strings.Join(d.path, ".")] += elapsed
+}
+
+// FieldTimings returns the time spent decoding each field since the last Reset, keyed by
+// dotted field path. A nested struct's duration includes the time spent on its own fields.
+// It is empty unless the decoder was created with WithFieldTiming.
+func (d *Decoder) FieldTimings() map[string]time.Duration {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return maps.Clone(d.timings)
+}
+
+// Reset clears the state accumulated by previous decode calls, such as the field sources
+// recorded with WithSourceTracking and the durations recorded with WithFieldTiming.
+// The decoder's options are kept.
+//
+// The results of concurrent calls are merged as each call returns; when a decoder is shared
+// between calls whose results should be told apart, call Reset between them.
+func (d *Decoder) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sources = nil
+	d.timings = nil
+}
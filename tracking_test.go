@@ -0,0 +1,144 @@
+package main
+
+import "testing"
+
+func TestSourceTracking(t *testing.T) {
+	type Config struct {
+		Host    string `gomap:"host"`
+		Port    int
+		Timeout int
+	}
+
+	defaults := map[string]interface{}{"host": "localhost", "Port": 80}
+	file := map[string]interface{}{"Port": 8080}
+	env := map[string]interface{}{"Timeout": 30}
+
+	d := NewDecoder(WithSourceTracking())
+	var dst Config
+	if err := d.MergeInto(&dst, defaults, file, env); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Config{Host: "localhost", Port: 8080, Timeout: 30}
+	if dst != want {
+		t.Errorf("expected %+v, got %+v", want, dst)
+	}
+
+	sources := d.FieldSources()
+	if sources["Host"] != 0 || sources["Port"] != 1 || sources["Timeout"] != 2 {
+		t.Errorf("unexpected field sources: %v", sources)
+	}
+	if info := d.FieldSourceInfo()["Host"]; info.Key != "host" {
+		t.Errorf("expected source key host, got %q", info.Key)
+	}
+
+	t.Run("nested paths", func(t *testing.T) {
+		type Service struct {
+			Config Config
+		}
+		d := NewDecoder(WithSourceTracking())
+		var svc Service
+		src := map[string]interface{}{"Config": map[string]interface{}{"Port": 1}}
+		if err := d.Decode(src, &svc); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := d.FieldSources()["Config.Port"]; !ok {
+			t.Errorf("expected nested path to be tracked, got %v", d.FieldSources())
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		d.Reset()
+		if len(d.FieldSources()) != 0 {
+			t.Errorf("expected no sources after reset, got %v", d.FieldSources())
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		d := NewDecoder()
+		var dst Config
+		if err := d.MergeInto(&dst, defaults, file); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(d.FieldSources()) != 0 {
+			t.Errorf("expected no tracking without option, got %v", d.FieldSources())
+		}
+	})
+}
+
+func TestDecoderReset(t *testing.T) {
+	type Config struct {
+		Host string
+		Port int
+	}
+
+	d := NewDecoder(WithSourceTracking(), WithGroups("public"))
+
+	var first Config
+	if err := d.Decode(map[string]interface{}{"Host": "a"}, &first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d.Reset()
+
+	var second Config
+	if err := d.Decode(map[string]interface{}{"Port": 1}, &second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sources := d.FieldSources()
+	if _, ok := sources["Host"]; ok || len(sources) != 1 {
+		t.Errorf("expected only the second call to be tracked, got %v", sources)
+	}
+	if !d.opts.SourceTracking || len(d.opts.Groups) != 1 {
+		t.Errorf("expected options to survive reset, got %+v", d.opts)
+	}
+}
+
+func TestFieldTiming(t *testing.T) {
+	type Inner struct {
+		Value int
+	}
+	type Record struct {
+		Name  string
+		Count int
+		Inner Inner
+	}
+
+	src := map[string]interface{}{
+		"Name":  "a",
+		"Count": 1,
+		"Inner": map[string]interface{}{"Value": 2},
+	}
+
+	d := NewDecoder(WithFieldTiming())
+	var dst Record
+	if err := d.Decode(src, &dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	timings := d.FieldTimings()
+	for _, path := range []string{"Name", "Count", "Inner", "Inner.Value"} {
+		if _, ok := timings[path]; !ok {
+			t.Errorf("expected timing for %s, got %v", path, timings)
+		}
+	}
+	if len(timings) != 4 {
+		t.Errorf("expected 4 timings, got %v", timings)
+	}
+
+	d.Reset()
+	if len(d.FieldTimings()) != 0 {
+		t.Errorf("expected no timings after reset, got %v", d.FieldTimings())
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		d := NewDecoder()
+		var dst Record
+		if err := d.Decode(src, &dst); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(d.FieldTimings()) != 0 {
+			t.Errorf("expected no timings without option, got %v", d.FieldTimings())
+		}
+	})
+}
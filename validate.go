@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Validate reports the error Decode would return for data and the struct type of prototype,
+// which may be a struct, a pointer to one or a nil pointer of that type, without modifying
+// prototype: data is decoded into a new zero value that is then discarded. Type conversions,
+// `required` tags, strict mode and the PreDecoder and PostDecoder hooks all run as they would
+// for Decode.
+func (d *Decoder) Validate(data interface{}, prototype interface{}) error {
+	t := reflect.TypeOf(prototype)
+	if t == nil || indirectType(t).Kind() != reflect.Struct {
+		err := fmt.Errorf("prototype must be a struct or a pointer to a struct, got %T", prototype)
+		return d.wrapError(prototype, err)
+	}
+	return d.wrapError(prototype, d.decodeSession(data, reflect.New(indirectType(t)).Interface()))
+}
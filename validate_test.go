@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	type User struct {
+		Name string `gomap:"name,required"`
+		Age  int    `gomap:"age"`
+	}
+
+	user := User{Name: "john", Age: 30}
+	d := NewDecoder(WithStrictMode())
+
+	if err := d.Validate(map[string]interface{}{"name": "jane", "age": 31}, &user); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user != (User{Name: "john", Age: 30}) {
+		t.Errorf("prototype was modified: %+v", user)
+	}
+
+	t.Run("type mismatch", func(t *testing.T) {
+		err := d.Validate(map[string]interface{}{"name": "jane", "age": "old"}, &user)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if user != (User{Name: "john", Age: 30}) {
+			t.Errorf("prototype was modified: %+v", user)
+		}
+	})
+
+	t.Run("missing required field", func(t *testing.T) {
+		var missing *MissingFieldsError
+		if err := d.Validate(map[string]interface{}{"age": 31}, User{}); !errors.As(err, &missing) {
+			t.Errorf("expected *MissingFieldsError, got %v", err)
+		}
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		var unknown *UnknownKeysError
+		err := d.Validate(map[string]interface{}{"name": "jane", "nmae": "x"}, (*User)(nil))
+		if !errors.As(err, &unknown) {
+			t.Errorf("expected *UnknownKeysError, got %v", err)
+		}
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		if err := d.Validate(map[string]interface{}{}, 42); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// parseBool parses the boolean spellings accepted in weak mode: true/false, 1/0 and yes/no,
+// ignoring case.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "true", "1", "yes":
+		return true, nil
+	case "false", "0", "no":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid boolean %q", s)
+	}
+}
+
+// parseString parses s into a bool or numeric dst. It reports false if dst has another kind.
+func parseString(dst reflect.Value, s string) (bool, error) {
+	switch dst.Kind() {
+	case reflect.Bool:
+		b, err := parseBool(s)
+		if err != nil {
+			return true, err
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, dst.Type().Bits())
+		if err != nil {
+			return true, err
+		}
+		dst.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, dst.Type().Bits())
+		if err != nil {
+			return true, err
+		}
+		dst.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, dst.Type().Bits())
+		if err != nil {
+			return true, err
+		}
+		dst.SetFloat(f)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// weakAssign performs the conversions enabled by WithWeakDecode: strings are parsed into bool
+// and numeric destinations, and numbers are formatted into string destinations. It reports
+// false if neither applies.
+func weakAssign(dst reflect.Value, src reflect.Value) (bool, error) {
+	switch src.Kind() {
+	case reflect.String:
+		return parseString(dst, src.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if dst.Kind() != reflect.String {
+			return false, nil
+		}
+		dst.SetString(fmt.Sprint(src.Interface()))
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// assignJSONNumber stores the json.Number n, as produced by json.Decoder.UseNumber, in a
+// numeric dst. It reports false if dst is not numeric.
+func assignJSONNumber(dst reflect.Value, n json.Number) (bool, error) {
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := n.Int64()
+		if err != nil {
+			return true, err
+		}
+		if dst.OverflowInt(v) {
+			return true, fmt.Errorf("number %s overflows %s", n, dst.Type())
+		}
+		dst.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(n.String(), 10, dst.Type().Bits())
+		if err != nil {
+			return true, err
+		}
+		dst.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := n.Float64()
+		if err != nil {
+			return true, err
+		}
+		dst.SetFloat(v)
+	default:
+		return false, nil
+	}
+	return true, nil
+}